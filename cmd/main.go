@@ -1,12 +1,24 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	neturl "net/url"
 	"os"
+	"os/signal"
+	"strings"
+	"time"
 
 	"github.com/Richd0tcom/schedrift/internal/config"
 	"github.com/Richd0tcom/schedrift/internal/db"
+	"github.com/Richd0tcom/schedrift/internal/diff"
+	"github.com/Richd0tcom/schedrift/internal/models"
 	"github.com/Richd0tcom/schedrift/internal/tui"
+	"github.com/Richd0tcom/schedrift/pkg/loader"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 )
@@ -18,25 +30,32 @@ func main() {
 		Short: "A tool to detect schema drift between database and code",
 		Long: `Schema Drift Detector (schemarift) is a CLI tool that helps you detect
 differences between your production database schema and your reference schema.
-It allows you to prevent unexpected schema changes and maintain consistency.`,
+It allows you to prevent unexpected schema changes and maintain consistency.
+
+Settings are resolved from command line flags first, then SCHEMA_DRIFT_*
+environment variables (e.g. SCHEMA_DRIFT_URL), then the config file
+(--config, or ` + config.DefaultConfigFile + ` in the current directory), then defaults.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// If no subcommand is provided, run the TUI
-			url, _ := cmd.Flags().GetString("url")
-			if url == "" {
-				// If no URL is provided, show help
+			cfg, err := config.LoadFromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			if cfg.DatabaseConfig.Url == "" && cfg.DatabaseConfig.DatabaseName == "" {
+				// If no database is configured, show help
 				return cmd.Help()
 			}
 
-			schemaName, _ := cmd.Flags().GetString("schema")
+			ctx, cancel := extractionContext(cmd.Context(), cfg.DatabaseConfig)
+			defer cancel()
 
-			dbConfig := config.DatabaseConfig{
-				Url: url,
-			}
 			// Create connection
-			conn, err := db.NewConnection(dbConfig)
+			conn, err := db.NewConnection(ctx, cfg.DatabaseConfig)
 			if err != nil {
-				return fmt.Errorf("failed to create database connector: %w", err)
+				return fmt.Errorf("failed to create database connector: %w", explainTimeout(ctx, cfg.DatabaseConfig, err))
 			}
+			defer conn.Close()
 
 			// Start the TUI with a loading message
 			tuiModel := tui.NewModel()
@@ -45,7 +64,7 @@ It allows you to prevent unexpected schema changes and maintain consistency.`,
 			// Start extraction in a goroutine
 			go func() {
 				// First show connection info
-				p.Send(tui.ConnectionMsg{Message: fmt.Sprintf("Connecting to %s...", url)})
+				p.Send(tui.ConnectionMsg{Message: fmt.Sprintf("Connecting to %s...", describeTarget(cfg.DatabaseConfig))})
 
 				// Extract schema
 				extractor, err := db.NewExtractor(conn)
@@ -54,10 +73,13 @@ It allows you to prevent unexpected schema changes and maintain consistency.`,
 					p.Send(tui.ErrorMsg{Err: fmt.Errorf("failed to create extractor: %w", err)})
 					return
 				}
+				extractor.OnProgress(func(ev models.ProgressEvent) {
+					p.Send(tui.ProgressMsg{Event: ev})
+				})
 
-				schema, err := extractor.Extract([]string{schemaName}, []string{})
+				schema, err := extractor.Extract(ctx, cfg.SchemaConfig)
 				if err != nil {
-					p.Send(tui.ErrorMsg{Err: fmt.Errorf("failed to extract schema: %w", err)})
+					p.Send(tui.ErrorMsg{Err: fmt.Errorf("failed to extract schema: %w", explainTimeout(ctx, cfg.DatabaseConfig, err))})
 					return
 				}
 
@@ -65,8 +87,10 @@ It allows you to prevent unexpected schema changes and maintain consistency.`,
 				p.Send(tui.SchemaFetchedMsg{Schema: schema})
 			}()
 
-			// Run the TUI
-			if _, err := p.Run(); err != nil {
+			// Run the TUI. Quitting it cancels an extraction that's still running
+			_, err = p.Run()
+			cancel()
+			if err != nil {
 				return fmt.Errorf("error running TUI: %w", err)
 			}
 
@@ -74,6 +98,10 @@ It allows you to prevent unexpected schema changes and maintain consistency.`,
 		},
 	}
 
+	// Connection, schema filter and output flags are shared by every command
+	config.SetupFlags(rootCmd.PersistentFlags())
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Print extraction progress to stderr")
+
 	// Add commands
 	rootCmd.AddCommand(createDumpCommand())
 	rootCmd.AddCommand(createCheckCommand())
@@ -97,37 +125,56 @@ func createDumpCommand() *cobra.Command {
 or stdout. The schema includes tables, columns, indices, constraints,
 and other database objects.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Parse flags
-			url, _ := cmd.Flags().GetString("url")
-			schemaName, _ := cmd.Flags().GetString("schema")
-			output, _ := cmd.Flags().GetString("output")
-
-			dbConfig := config.DatabaseConfig{
-				Url: url,
+			cfg, err := config.LoadFromFlags(cmd.Flags())
+			if err != nil {
+				return err
 			}
+
+			ctx, cancel := extractionContext(cmd.Context(), cfg.DatabaseConfig)
+			defer cancel()
+
 			// Create connection
-			conn, err := db.NewConnection(dbConfig)
+			conn, err := db.NewConnection(ctx, cfg.DatabaseConfig)
 			if err != nil {
-				return fmt.Errorf("failed to create database connector: %w", err)
+				return fmt.Errorf("failed to create database connector: %w", explainTimeout(ctx, cfg.DatabaseConfig, err))
 			}
+			defer conn.Close()
 
 			// Extract schema
 			extractor, err := db.NewExtractor(conn)
 			if err != nil {
 				return fmt.Errorf("failed to create extractor: %w", err)
 			}
-			schema, err := extractor.Extract([]string{schemaName}, []string{})
+			if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+				extractor.OnProgress(printProgress)
+			}
+			schema, err := extractor.Extract(ctx, cfg.SchemaConfig)
 			if err != nil {
-				return fmt.Errorf("failed to extract schema: %w", err)
+				return fmt.Errorf("failed to extract schema: %w", explainTimeout(ctx, cfg.DatabaseConfig, err))
+			}
+
+			var content string
+			switch cfg.OutputConfig.Format {
+			case "sql":
+				content = schema.ToSQL()
+			case "json":
+				data, err := json.MarshalIndent(schema, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to encode schema: %w", err)
+				}
+				content = string(data)
+			default:
+				return fmt.Errorf("unsupported output format %q (expected sql or json)", cfg.OutputConfig.Format)
 			}
 
 			// Write to output
+			output := cfg.OutputConfig.File
 			if output == "" {
 				// Write to stdout
-				fmt.Println(schema.ToSQL())
+				fmt.Println(content)
 			} else {
 				// Write to file
-				err = os.WriteFile(output, []byte(schema.ToSQL()), 0644)
+				err = os.WriteFile(output, []byte(content), 0644)
 				if err != nil {
 					return fmt.Errorf("failed to write schema to file: %w", err)
 				}
@@ -138,13 +185,10 @@ and other database objects.`,
 		},
 	}
 
-	// Add local flags
-	dumpCmd.Flags().String("output", "", "Output file path (stdout if not specified)")
-
 	return dumpCmd
 }
 
-// Create the check command (placeholder for now)
+// Create the check command
 func createCheckCommand() *cobra.Command {
 	checkCmd := &cobra.Command{
 		Use:   "check",
@@ -153,56 +197,296 @@ func createCheckCommand() *cobra.Command {
 schema file. Report differences and optionally fail if significant differences
 are found.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// This will be implemented later
-			fmt.Println("Check command not yet implemented")
+			cfg, err := config.LoadFromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			// Parse flags
+			reference, _ := cmd.Flags().GetString("reference")
+			failOn, _ := cmd.Flags().GetString("fail-on")
+
+			if reference == "" {
+				return fmt.Errorf("a reference schema file or directory is required (--reference)")
+			}
+
+			// The reference file isn't schema qualified, so it's compared against a single schema.
+			// MySQL includes none by default, extracting just the database it connects to
+			if len(cfg.SchemaConfig.IncludedSchemas) > 1 {
+				return fmt.Errorf("check compares a single schema, but %d were included", len(cfg.SchemaConfig.IncludedSchemas))
+			}
+
+			minSeverity, err := diff.ParseSeverity(failOn)
+			if err != nil {
+				return err
+			}
+
+			tables, err := cfg.SchemaConfig.TableFilter()
+			if err != nil {
+				return err
+			}
+
+			// Load reference schema
+			ld := loader.NewSchemaLoader(&loader.LoaderConfig{})
+			refSchema, err := ld.LoadFromPath(reference)
+			if err != nil {
+				return fmt.Errorf("failed to load reference schema: %w", err)
+			}
+			// Tables filtered out of the extraction would otherwise show up as removed. The
+			// reference is normalized first, so that schema-qualified names match the patterns
+			refSchema = ld.FilterTables(ld.NormalizeSchema(refSchema), tables.Match)
+
+			ctx, cancel := extractionContext(cmd.Context(), cfg.DatabaseConfig)
+			defer cancel()
+
+			// Create connection
+			conn, err := db.NewConnection(ctx, cfg.DatabaseConfig)
+			if err != nil {
+				return fmt.Errorf("failed to create database connector: %w", explainTimeout(ctx, cfg.DatabaseConfig, err))
+			}
+			defer conn.Close()
+
+			// Extract schema
+			extractor, err := db.NewExtractor(conn)
+			if err != nil {
+				return fmt.Errorf("failed to create extractor: %w", err)
+			}
+			if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+				extractor.OnProgress(printProgress)
+			}
+			dbSchema, err := extractor.Extract(ctx, cfg.SchemaConfig)
+			if err != nil {
+				return fmt.Errorf("failed to extract schema: %w", explainTimeout(ctx, cfg.DatabaseConfig, err))
+			}
+
+			var schemaName string
+			switch {
+			case len(cfg.SchemaConfig.IncludedSchemas) == 1:
+				schemaName = cfg.SchemaConfig.IncludedSchemas[0]
+			case len(dbSchema.Schemas) == 1:
+				schemaName = dbSchema.Schemas[0].Name
+			default:
+				return fmt.Errorf("check compares a single schema, but %d were extracted (select one with --include)", len(dbSchema.Schemas))
+			}
+
+			liveSchema := &models.Schema{Name: schemaName}
+			for _, sch := range dbSchema.Schemas {
+				if sch.Name == schemaName {
+					liveSchema = sch
+					break
+				}
+			}
+
+			if cfg.SchemaConfig.IgnoreOwnership {
+				refSchema = ld.WithoutOwners(refSchema)
+				liveSchema = ld.WithoutOwners(liveSchema)
+			}
+
+			// The reference is the expected state, so changes are reported relative to it
+			schemaDiff := diff.BuildDiff(refSchema, ld.NormalizeSchema(liveSchema))
+			fmt.Print(schemaDiff.ToText())
+
+			if schemaDiff.HasSeverity(minSeverity) {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("schema drift detected: %d change(s) at or above %s severity",
+					schemaDiff.CountSeverity(minSeverity), minSeverity)
+			}
+
 			return nil
 		},
 	}
 
-	// Add flags (we'll implement this later)
+	// Add local flags
+	checkCmd.Flags().StringP("reference", "r", "", "Reference schema file or directory")
+	checkCmd.Flags().String("fail-on", string(diff.High), "Minimum severity that causes a non-zero exit (none, low, medium, high)")
 
 	return checkCmd
 }
 
-// Create the diff command (placeholder for now)
+// Create the diff command
 func createDiffCommand() *cobra.Command {
 	diffCmd := &cobra.Command{
-		Use:   "diff",
+		Use:   "diff <old> <new>",
 		Short: "Show differences between two schema files",
 		Long: `Compare two schema files and show the differences between them.
-This command does not connect to a database but works with schema files.`,
+This command does not connect to a database but works with schema files.
+Either argument may be a directory, in which case the most likely schema
+file inside it is used.`,
+		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// This will be implemented later
-			fmt.Println("Diff command not yet implemented")
+			ld := loader.NewSchemaLoader(&loader.LoaderConfig{})
+
+			oldSchema, err := ld.LoadFromPath(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to load %s: %w", args[0], err)
+			}
+
+			newSchema, err := ld.LoadFromPath(args[1])
+			if err != nil {
+				return fmt.Errorf("failed to load %s: %w", args[1], err)
+			}
+
+			cfg, err := config.LoadFromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			if cfg.SchemaConfig.IgnoreOwnership {
+				oldSchema = ld.WithoutOwners(oldSchema)
+				newSchema = ld.WithoutOwners(newSchema)
+			}
+
+			schemaDiff := diff.BuildDiff(ld.NormalizeSchema(oldSchema), ld.NormalizeSchema(newSchema))
+			fmt.Print(schemaDiff.ToText())
+
 			return nil
 		},
 	}
 
-	// Add flags (we'll implement this later)
-
 	return diffCmd
 }
 
-// Create the init command (placeholder for now)
+// Create the init command
 func createInitCommand() *cobra.Command {
 	initCmd := &cobra.Command{
-		Use:   "init",
+		Use:   "init [path]",
 		Short: "Initialize configuration file",
 		Long: `Create a default configuration file in the current directory
 or at the specified path. The configuration file contains settings for
 database connections, schema comparison, and notifications.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// This will be implemented later
-			fmt.Println("Init command not yet implemented")
+			path := config.DefaultConfigFile
+			if len(args) == 1 {
+				path = args[0]
+			}
+
+			force, _ := cmd.Flags().GetBool("force")
+			interactive, _ := cmd.Flags().GetBool("interactive")
+			url, _ := cmd.Flags().GetString("url")
+			schemas, _ := cmd.Flags().GetStringSlice("include")
+
+			// The config isn't loaded here, so that an existing file or PGPASSWORD never ends up in the output
+			cfg := config.DefaultConfig()
+			cfg.DatabaseConfig.Url = url
+			if len(schemas) > 0 {
+				cfg.SchemaConfig.IncludedSchemas = schemas
+			}
+
+			// a MySQL URL gets MySQL's port, and its database rather than the public schema
+			useDriverDefaults := func() {
+				if config.DriverFromURL(cfg.DatabaseConfig.Url) != "mysql" {
+					return
+				}
+				cfg.DatabaseConfig.Port = config.DefaultMySQLPort
+				if !cmd.Flags().Changed("include") {
+					cfg.SchemaConfig.IncludedSchemas = []string{}
+				}
+			}
+			useDriverDefaults()
+
+			if interactive {
+				reader := bufio.NewReader(cmd.InOrStdin())
+
+				answer, err := prompt(reader, cmd.OutOrStdout(), "Database connection URL", cfg.DatabaseConfig.Url)
+				if err != nil {
+					return err
+				}
+				cfg.DatabaseConfig.Url = answer
+				useDriverDefaults()
+
+				answer, err = prompt(reader, cmd.OutOrStdout(), "Schemas to include (comma separated)", strings.Join(cfg.SchemaConfig.IncludedSchemas, ","))
+				if err != nil {
+					return err
+				}
+				cfg.SchemaConfig.IncludedSchemas = splitList(answer)
+			}
+
+			if err := config.WriteConfigFile(path, cfg, force); err != nil {
+				return err
+			}
+
+			fmt.Printf("Configuration written to %s\n", path)
 			return nil
 		},
 	}
 
-	// Add flags (we'll implement this later)
+	// Add local flags
+	initCmd.Flags().Bool("force", false, "Overwrite an existing configuration file")
+	initCmd.Flags().BoolP("interactive", "i", false, "Prompt for connection settings")
 
 	return initCmd
 }
 
+// extractionContext bounds the work against the database by --timeout and cancels it on Ctrl+C
+func extractionContext(parent context.Context, cfg config.DatabaseConfig) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(parent, os.Interrupt)
+	if cfg.Timeout <= 0 {
+		return ctx, stop
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// printProgress reports extraction progress on stderr, keeping stdout for the output itself
+func printProgress(ev models.ProgressEvent) {
+	fmt.Fprintf(os.Stderr, "[%7s] %s\n", ev.Elapsed.Round(time.Millisecond), ev)
+}
+
+// explainTimeout points at --timeout when err was caused by the extraction deadline passing
+func explainTimeout(ctx context.Context, cfg config.DatabaseConfig, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s (see --timeout): %w", cfg.Timeout, err)
+	}
+	return err
+}
+
+// describeTarget returns a printable description of the configured database without credentials
+func describeTarget(cfg config.DatabaseConfig) string {
+	if cfg.Url != "" {
+		if u, err := neturl.Parse(cfg.Url); err == nil && u.Scheme != "" {
+			return u.Redacted()
+		}
+		return cfg.Driver + " database"
+	}
+	return fmt.Sprintf("%s:%s/%s", cfg.Host, cfg.Port, cfg.DatabaseName)
+}
+
+// prompt asks a question on out and reads a single line answer, falling back to def when empty
+func prompt(reader *bufio.Reader, out io.Writer, question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(out, "%s: ", question)
+	}
+
+	answer, err := reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read answer: %w", err)
+	}
+
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// splitList splits a comma separated answer into trimmed, non-empty items
+func splitList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // Create the version command
 func createVersionCommand() *cobra.Command {
 	return &cobra.Command{
@@ -1,9 +1,14 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -18,6 +23,12 @@ type DatabaseConfig struct {
 	Password     string `mapstructure:"password"`
 	DatabaseName string `mapstructure:"database_name"`
 	SSLMode      string `mapstructure:"sslmode"`
+
+	Timeout          time.Duration `mapstructure:"timeout"`           // whole extraction, 0 for no limit
+	StatementTimeout time.Duration `mapstructure:"statement_timeout"` // each catalog query, 0 for no limit
+	LockTimeout      time.Duration `mapstructure:"lock_timeout"`      // waiting on a lock, 0 for no limit
+
+	Workers int `mapstructure:"workers"` // concurrent extraction queries
 }
 
 type SchemaConfig struct {
@@ -25,6 +36,99 @@ type SchemaConfig struct {
 	ExcludedSchemas []string `mapstructure:"excluded_schemas"`
 	IncludedTables  []string `mapstructure:"included_tables"`
 	ExcludedTables  []string `mapstructure:"excluded_tables"`
+	IgnoreOwnership bool     `mapstructure:"ignore_ownership"`
+}
+
+// TableFilter holds table patterns translated to anchored regular expressions that can be
+// evaluated both in Go and by PostgreSQL's ~ operator
+type TableFilter struct {
+	Include []string // every table is included when empty
+	Exclude []string
+
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// TableFilter compiles the included and excluded table patterns. A pattern is either a glob
+// (audit_*, tmp_?) or, when it contains other regex syntax, a regular expression matched
+// against the whole table name (tmp_.*). Patterns prefixed with ! exclude matching tables
+// from either list.
+func (sc SchemaConfig) TableFilter() (*TableFilter, error) {
+	filter := &TableFilter{
+		Include: []string{},
+		Exclude: []string{},
+	}
+
+	// a copy, as appending to sc.ExcludedTables could write into the caller's backing array
+	excluded := slices.Clone(sc.ExcludedTables)
+	for _, pattern := range sc.IncludedTables {
+		if negated, ok := strings.CutPrefix(pattern, "!"); ok {
+			excluded = append(excluded, negated)
+			continue
+		}
+		expr, re, err := tablePattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		filter.Include = append(filter.Include, expr)
+		filter.include = append(filter.include, re)
+	}
+
+	for _, pattern := range excluded {
+		expr, re, err := tablePattern(strings.TrimPrefix(pattern, "!"))
+		if err != nil {
+			return nil, err
+		}
+		filter.Exclude = append(filter.Exclude, expr)
+		filter.exclude = append(filter.exclude, re)
+	}
+
+	return filter, nil
+}
+
+// Match reports whether a table passes the filter
+func (f *TableFilter) Match(table string) bool {
+	matchAny := func(exprs []*regexp.Regexp) bool {
+		for _, re := range exprs {
+			if re.MatchString(table) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(f.include) > 0 && !matchAny(f.include) {
+		return false
+	}
+	return !matchAny(f.exclude)
+}
+
+// regexSyntax holds characters that only appear in regular expression table patterns
+const regexSyntax = `.^$+()[]{}|\`
+
+// tablePattern translates a table pattern to an anchored regular expression, returned both as
+// text and compiled
+func tablePattern(pattern string) (string, *regexp.Regexp, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return "", nil, fmt.Errorf("empty table pattern")
+	}
+
+	var expr string
+	if strings.ContainsAny(pattern, regexSyntax) {
+		expr = "^(?:" + strings.TrimSuffix(strings.TrimPrefix(pattern, "^"), "$") + ")$"
+	} else {
+		expr = regexp.QuoteMeta(pattern)
+		expr = strings.ReplaceAll(expr, `\*`, ".*")
+		expr = strings.ReplaceAll(expr, `\?`, ".")
+		expr = "^" + expr + "$"
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid table pattern %q: %w", pattern, err)
+	}
+	return expr, re, nil
 }
 
 type OutputConfig struct {
@@ -38,70 +142,298 @@ type Config struct {
 	OutputConfig   OutputConfig   `mapstructure:"output"`
 }
 
+// flagKeys maps command line flags to their config file keys. Each key can also
+// be set through the SCHEMA_DRIFT_<FLAG> environment variable, e.g. SCHEMA_DRIFT_URL
+var flagKeys = map[string]string{
+	"url":               "database.url",
+	"driver":            "database.driver",
+	"host":              "database.host",
+	"port":              "database.port",
+	"user":              "database.user",
+	"password":          "database.password",
+	"dbname":            "database.database_name",
+	"sslmode":           "database.sslmode",
+	"timeout":           "database.timeout",
+	"statement-timeout": "database.statement_timeout",
+	"lock-timeout":      "database.lock_timeout",
+	"workers":           "database.workers",
+	"include":           "schema.included_schemas",
+	"exclude":           "schema.excluded_schemas",
+	"include-tables":    "schema.included_tables",
+	"exclude-tables":    "schema.excluded_tables",
+	"ignore-ownership":  "schema.ignore_ownership",
+	"format":            "output.format",
+	"output":            "output.file",
+}
+
 func SetupFlags(flags *pflag.FlagSet) {
 	//DB
 	flags.String("url", "", "Database connection URL")
+	flags.String("driver", "", "Database driver (inferred from the URL scheme if not specified)")
 	flags.String("host", "localhost", "Database host")
 	flags.Int("port", 5432, "Database port")
 	flags.String("user", "", "Database user")
 	flags.String("password", "", "Database password")
 	flags.String("dbname", "", "Database name")
 	flags.String("sslmode", "prefer", "SSL mode (disable, prefer, require, verify-ca, verify-full)")
+	flags.Duration("timeout", 0, "Maximum time for the whole extraction (0 for no limit)")
+	flags.Duration("statement-timeout", DefaultStatementTimeout, "Maximum time for each catalog query (0 for no limit)")
+	flags.Duration("lock-timeout", DefaultLockTimeout, "Maximum time to wait for a lock, e.g. behind a migration (0 for no limit)")
+	flags.Int("workers", DefaultWorkers, "Number of catalog queries to run concurrently")
 
 	//schema
 	flags.StringSlice("include", []string{"public"}, "Schemas to include")
 	flags.StringSlice("exclude", []string{}, "Schemas to exclude")
-	flags.StringSlice("include-tables", []string{}, "Tables to include")
-	flags.StringSlice("exclude-tables", []string{}, "Tables to exclude")
+	flags.StringSlice("include-tables", []string{}, "Table patterns to include, as globs (audit_*) or regexes (tmp_.*); prefix with ! to exclude")
+	flags.StringSlice("exclude-tables", []string{}, "Table patterns to exclude, as globs or regexes")
+	flags.Bool("ignore-ownership", false, "Don't report objects whose owner differs from the reference")
 
 	//output
 	flags.String("format", "sql", "output format (sql, json)")
 	flags.String("output", "", "Output file (stdout if not specified)")
-	flags.String("config", "", "Configuration file path")
+	flags.String("config", "", "Configuration file path (defaults to "+DefaultConfigFile+" if present)")
 }
 
 func setDefaults(v *viper.Viper) {
 	v.SetDefault("database.host", "localhost")
 	v.SetDefault("database.port", 5432)
 	v.SetDefault("database.sslmode", "prefer") //preffered ?
-	v.SetDefault("schema.include", []string{"public"})
+	v.SetDefault("database.statement_timeout", DefaultStatementTimeout)
+	v.SetDefault("database.lock_timeout", DefaultLockTimeout)
+	v.SetDefault("database.workers", DefaultWorkers)
+	v.SetDefault("schema.included_schemas", []string{"public"})
 	v.SetDefault("output.format", "sql")
 }
 
+// LoadFromFlags resolves the full config. Values are taken in this order of precedence:
+// explicitly set flags, SCHEMA_DRIFT_* environment variables, the config file, then defaults.
 func LoadFromFlags(flags *pflag.FlagSet) (*Config, error) {
 	v := viper.New()
 
-	if err := v.BindPFlags(flags); err != nil {
-		return nil, fmt.Errorf("error binding flags: %w", err)
+	setDefaults(v)
+
+	for flag, key := range flagKeys {
+		f := flags.Lookup(flag)
+		if f == nil {
+			continue
+		}
+		if err := v.BindPFlag(key, f); err != nil {
+			return nil, fmt.Errorf("error binding flag %s: %w", flag, err)
+		}
+
+		if err := v.BindEnv(key, envName(flag)); err != nil {
+			return nil, fmt.Errorf("error binding env %s: %w", envName(flag), err)
+		}
 	}
 
-	v.SetEnvPrefix("SCHEMA_DRIFT")
-	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
-	v.AutomaticEnv()
+	// PGPASSWORD is honoured as a fallback so passwords don't have to be passed on the command line
+	if err := v.BindEnv("database.password", "SCHEMA_DRIFT_PASSWORD", "PGPASSWORD"); err != nil {
+		return nil, fmt.Errorf("error binding env PGPASSWORD: %w", err)
+	}
 
 	//checks if a config file is being used
-	if cfgFile := v.GetString("config"); cfgFile != "" {
+	cfgFile, _ := flags.GetString("config")
+	if cfgFile == "" {
+		cfgFile = os.Getenv("SCHEMA_DRIFT_CONFIG")
+	}
+	if cfgFile == "" {
+		if _, err := os.Stat(DefaultConfigFile); err == nil {
+			cfgFile = DefaultConfigFile
+		}
+	}
+
+	if cfgFile != "" {
 		v.SetConfigFile(cfgFile)
 		if err := v.ReadInConfig(); err != nil {
 			return nil, fmt.Errorf("error reading config file: %w", err)
 		}
 	}
 
-	setDefaults(v)
-
-	// Override with environment variables for sensitive information
-	if password := os.Getenv("PGPASSWORD"); password != "" {
-		v.Set("password", password)
-	}
-
 	cfg := Config{}
 
 	if err := v.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("error unmarshalling config: %w", err)
 	}
 
-	cfg.OutputConfig.File = v.GetString("config")
+	if cfg.DatabaseConfig.Driver == "" {
+		cfg.DatabaseConfig.Driver = DriverFromURL(cfg.DatabaseConfig.Url)
+	}
+
+	// the port and schema defaults are PostgreSQL's. MySQL's databases take the place of
+	// schemas, so it extracts the database it connects to unless others are included
+	if cfg.DatabaseConfig.Driver == "mysql" {
+		if !isSet(v, flags, "port") {
+			cfg.DatabaseConfig.Port = DefaultMySQLPort
+		}
+		if !isSet(v, flags, "include") {
+			cfg.SchemaConfig.IncludedSchemas = []string{}
+		}
+	}
 
 	return &cfg, nil
 
 }
+
+// envName returns the environment variable that sets a flag's value
+func envName(flag string) string {
+	return "SCHEMA_DRIFT_" + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// isSet reports whether a flag's value was given on the command line, in the environment or
+// in the config file, rather than left at its default
+func isSet(v *viper.Viper, flags *pflag.FlagSet, flag string) bool {
+	if f := flags.Lookup(flag); f != nil && f.Changed {
+		return true
+	}
+	if _, ok := os.LookupEnv(envName(flag)); ok {
+		return true
+	}
+	return v.InConfig(flagKeys[flag])
+}
+
+// DriverFromURL infers the database driver from a connection URL's scheme.
+// Key/value connection strings and empty URLs are assumed to be PostgreSQL.
+func DriverFromURL(url string) string {
+	scheme, _, found := strings.Cut(url, "://")
+	if !found {
+		return "postgres"
+	}
+
+	switch strings.ToLower(scheme) {
+	case "postgres", "postgresql":
+		return "postgres"
+	case "mysql", "mariadb":
+		return "mysql"
+	case "sqlite", "sqlite3":
+		return "sqlite"
+	default:
+		return strings.ToLower(scheme)
+	}
+}
+
+// DefaultConfigFile is the file name written by `schedrift init`
+const DefaultConfigFile = ".schedrift.yaml"
+
+// Extraction only reads the catalog, so it gives up quickly rather than queueing behind
+// an ACCESS EXCLUSIVE lock and blocking every query that arrives after it
+const (
+	DefaultStatementTimeout = time.Minute
+	DefaultLockTimeout      = 5 * time.Second
+)
+
+// DefaultMySQLPort is used instead of PostgreSQL's port when the driver is mysql
+const DefaultMySQLPort = "3306"
+
+// DefaultWorkers is the number of concurrent extraction queries, each on its own connection
+const DefaultWorkers = 4
+
+// DefaultConfig returns a config populated with the same defaults used when loading
+func DefaultConfig() *Config {
+	return &Config{
+		DatabaseConfig: DatabaseConfig{
+			Host:    "localhost",
+			Port:    "5432",
+			SSLMode: "prefer",
+
+			StatementTimeout: DefaultStatementTimeout,
+			LockTimeout:      DefaultLockTimeout,
+
+			Workers: DefaultWorkers,
+		},
+		SchemaConfig: SchemaConfig{
+			IncludedSchemas: []string{"public"},
+			ExcludedSchemas: []string{},
+			IncludedTables:  []string{},
+			ExcludedTables:  []string{},
+		},
+		OutputConfig: OutputConfig{
+			Format: "sql",
+		},
+	}
+}
+
+// ToYAML renders the config as a commented YAML document
+func (c *Config) ToYAML() string {
+	var sb strings.Builder
+
+	sb.WriteString("# schedrift configuration\n")
+	sb.WriteString("# Values can be overridden by command line flags and SCHEMA_DRIFT_* environment variables.\n\n")
+
+	sb.WriteString("database:\n")
+	sb.WriteString("  # Full connection URL. When set, the individual connection settings below are ignored.\n")
+	sb.WriteString(fmt.Sprintf("  url: %s\n", yamlString(c.DatabaseConfig.Url)))
+	sb.WriteString("  # Database driver (postgres, mysql, sqlite). Inferred from the url scheme when empty.\n")
+	sb.WriteString(fmt.Sprintf("  driver: %s\n", yamlString(c.DatabaseConfig.Driver)))
+	sb.WriteString("  # Database host\n")
+	sb.WriteString(fmt.Sprintf("  host: %s\n", yamlString(c.DatabaseConfig.Host)))
+	sb.WriteString("  # Database port (3306 is used for mysql unless a port is set)\n")
+	sb.WriteString(fmt.Sprintf("  port: %s\n", yamlString(c.DatabaseConfig.Port)))
+	sb.WriteString("  # Database user\n")
+	sb.WriteString(fmt.Sprintf("  user: %s\n", yamlString(c.DatabaseConfig.User)))
+	sb.WriteString("  # Database password. Prefer the PGPASSWORD environment variable over storing it here.\n")
+	sb.WriteString(fmt.Sprintf("  password: %s\n", yamlString(c.DatabaseConfig.Password)))
+	sb.WriteString("  # Database name\n")
+	sb.WriteString(fmt.Sprintf("  database_name: %s\n", yamlString(c.DatabaseConfig.DatabaseName)))
+	sb.WriteString("  # SSL mode (disable, prefer, require, verify-ca, verify-full)\n")
+	sb.WriteString(fmt.Sprintf("  sslmode: %s\n", yamlString(c.DatabaseConfig.SSLMode)))
+	sb.WriteString("  # Maximum time for the whole extraction (0s for no limit)\n")
+	sb.WriteString(fmt.Sprintf("  timeout: %s\n", yamlString(c.DatabaseConfig.Timeout.String())))
+	sb.WriteString("  # Maximum time for each catalog query (0s for no limit)\n")
+	sb.WriteString(fmt.Sprintf("  statement_timeout: %s\n", yamlString(c.DatabaseConfig.StatementTimeout.String())))
+	sb.WriteString("  # Maximum time to wait for a lock held by e.g. a migration (0s for no limit)\n")
+	sb.WriteString(fmt.Sprintf("  lock_timeout: %s\n", yamlString(c.DatabaseConfig.LockTimeout.String())))
+	sb.WriteString("  # Number of catalog queries to run concurrently, each on its own connection\n")
+	sb.WriteString(fmt.Sprintf("  workers: %d\n\n", c.DatabaseConfig.Workers))
+
+	sb.WriteString("schema:\n")
+	sb.WriteString("  # Database schemas to extract and compare. For mysql these are databases, and the database\n")
+	sb.WriteString("  # named in the url is used when this is left out. A sqlite database has a single schema,\n")
+	sb.WriteString("  # which is given the one name listed here\n")
+	sb.WriteString(fmt.Sprintf("  included_schemas: %s\n", yamlList(c.SchemaConfig.IncludedSchemas)))
+	sb.WriteString("  # Database schemas to skip, even if they are included above\n")
+	sb.WriteString(fmt.Sprintf("  excluded_schemas: %s\n", yamlList(c.SchemaConfig.ExcludedSchemas)))
+	sb.WriteString("  # Tables to compare (all tables when empty). Entries are globs (audit_*) or regular\n")
+	sb.WriteString("  # expressions (tmp_.*) matched against the whole table name; prefix one with ! to exclude it\n")
+	sb.WriteString(fmt.Sprintf("  included_tables: %s\n", yamlList(c.SchemaConfig.IncludedTables)))
+	sb.WriteString("  # Table patterns to skip, even if they are included above\n")
+	sb.WriteString(fmt.Sprintf("  excluded_tables: %s\n", yamlList(c.SchemaConfig.ExcludedTables)))
+	sb.WriteString("  # Don't compare object owners, e.g. when each environment uses its own roles\n")
+	sb.WriteString(fmt.Sprintf("  ignore_ownership: %t\n\n", c.SchemaConfig.IgnoreOwnership))
+
+	sb.WriteString("output:\n")
+	sb.WriteString("  # Output format (sql, json)\n")
+	sb.WriteString(fmt.Sprintf("  format: %s\n", yamlString(c.OutputConfig.Format)))
+	sb.WriteString("  # Output file (stdout when empty)\n")
+	sb.WriteString(fmt.Sprintf("  file: %s\n", yamlString(c.OutputConfig.File)))
+
+	return sb.String()
+}
+
+// WriteConfigFile writes cfg to path as YAML, refusing to replace an existing file unless force is set
+func WriteConfigFile(path string, cfg *Config, force bool) error {
+	if !force {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("config file %s already exists (use --force to overwrite)", path)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("error checking config file: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(cfg.ToYAML()), 0600); err != nil {
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+
+	return nil
+}
+
+func yamlString(s string) string {
+	return strconv.Quote(s)
+}
+
+func yamlList(items []string) string {
+	quoted := make([]string, 0, len(items))
+	for _, item := range items {
+		quoted = append(quoted, yamlString(item))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
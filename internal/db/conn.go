@@ -1,12 +1,15 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
 
 	"github.com/Richd0tcom/schedrift/internal/config"
+	"github.com/Richd0tcom/schedrift/internal/db/mysql"
 	"github.com/Richd0tcom/schedrift/internal/db/postgres"
+	"github.com/Richd0tcom/schedrift/internal/db/sqlite"
 	"github.com/Richd0tcom/schedrift/internal/models"
 )
 
@@ -15,48 +18,77 @@ type DatabaseDriver string
 const (
 	PostgreSQL DatabaseDriver = "postgres"
 	MySQL      DatabaseDriver = "mysql"
+	SQLite     DatabaseDriver = "sqlite"
 )
 
 type Connection interface {
 	Close() error
 	DB() *sql.DB
-	Query(query string, args ...any) (*sql.Rows, error)
-	QueryRow(query string, args ...any) *sql.Row
-	Exec(query string, args ...any) (sql.Result, error)
-	GetVersion() (string, error)
+	Query(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRow(ctx context.Context, query string, args ...any) *sql.Row
+	Exec(ctx context.Context, query string, args ...any) (sql.Result, error)
+	// BeginSnapshot starts a read-only transaction that sees a single consistent snapshot of
+	// the database, importing snapshotID when the driver supports it
+	BeginSnapshot(ctx context.Context, snapshotID string) (*sql.Tx, error)
+	GetVersion(ctx context.Context) (string, error)
 	GetDriverName() string
 }
 
-func NewConnection(cfg config.DatabaseConfig) (Connection, error) {
+func NewConnection(ctx context.Context, cfg config.DatabaseConfig) (Connection, error) {
 	dbType := DatabaseDriver(strings.ToLower(cfg.Driver))
 
 	switch dbType {
 		case PostgreSQL:
-			conn, err:= postgres.NewConnection(cfg)
+			conn, err:= postgres.NewConnection(ctx, cfg)
+
+			if err != nil {
+				return nil, err
+			}
 			conn.DriverName = string(PostgreSQL)
 
+			return conn, err
+
+		case MySQL:
+			conn, err := mysql.NewConnection(ctx, cfg)
+
+			if err != nil {
+				return nil, err
+			}
+			conn.DriverName = string(MySQL)
+
+			return conn, err
+
+		case SQLite:
+			conn, err := sqlite.NewConnection(ctx, cfg)
+
 			if err != nil {
 				return nil, err
 			}
-			
+			conn.DriverName = string(SQLite)
 
 			return conn, err
 
 
 		default:
-			return nil, fmt.Errorf("invalid driver type")
+			return nil, fmt.Errorf("invalid driver type %q", cfg.Driver)
 
 	}
 }
 
 type Extractor interface {
-	Extract(includedSchemas []string, excludedSchemas []string) (*models.DatabaseSchema, error)
+	Extract(ctx context.Context, cfg config.SchemaConfig) (*models.DatabaseSchema, error)
+	// OnProgress sets a handler for the progress events of subsequent extractions
+	OnProgress(handler models.ProgressFunc)
 }
 
 func NewExtractor(conn Connection) (Extractor, error) {
 	switch  {
 	case conn.GetDriverName() == string(PostgreSQL):
         return postgres.NewPGExtractor(conn.(*postgres.PGConnection)), nil
+	case conn.GetDriverName() == string(MySQL):
+		return mysql.NewMySQLExtractor(conn.(*mysql.MySQLConnection)), nil
+	case conn.GetDriverName() == string(SQLite):
+		return sqlite.NewSQLiteExtractor(conn.(*sqlite.SQLiteConnection)), nil
     default:
         return 	nil, fmt.Errorf("invalid driver type")
 	}
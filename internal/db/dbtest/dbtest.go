@@ -0,0 +1,140 @@
+// Package dbtest provides a database/sql driver answering queries from canned results, so
+// that the extractors' queries can be tested without a database server
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Query is a canned answer to any query containing Match. When Filter is set it is given the
+// query's arguments, and picks the rows to answer with from Rows, or returns false to leave
+// the query to the next Query
+type Query struct {
+	Match   string
+	Columns []string
+	Rows    [][]driver.Value
+	Filter  func(args []driver.Value) ([][]driver.Value, bool, error)
+}
+
+// Connector is a database/sql connector whose connections answer queries from a fixed list of
+// Query. Every query waits for roundTrip, standing in for the network, and is counted in
+// Count. Statements run with Exec, like session settings, always succeed
+type Connector struct {
+	Count atomic.Int64
+
+	queries   []Query
+	roundTrip time.Duration
+}
+
+// Open returns a database backed by the given queries, and the connector counting them
+func Open(roundTrip time.Duration, queries ...Query) (*sql.DB, *Connector) {
+	c := &Connector{queries: queries, roundTrip: roundTrip}
+	return sql.OpenDB(c), c
+}
+
+func (c *Connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{connector: c}, nil
+}
+
+func (c *Connector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+// answer returns the rows of the first query matching the SQL and its arguments
+func (c *Connector) answer(query string, args []driver.Value) (driver.Rows, error) {
+	for _, q := range c.queries {
+		if !strings.Contains(query, q.Match) {
+			continue
+		}
+		if q.Filter == nil {
+			return &rows{columns: q.Columns, rows: q.Rows}, nil
+		}
+
+		filtered, ok, err := q.Filter(args)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &rows{columns: q.Columns, rows: filtered}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unexpected query with args %v: %s", args, query)
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, fmt.Errorf("the fake driver is only opened through its connector")
+}
+
+type conn struct {
+	connector *Connector
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("the fake driver doesn't prepare statements")
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return tx{}, nil
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return tx{}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.connector.Count.Add(1)
+	if c.connector.roundTrip > 0 {
+		time.Sleep(c.connector.roundTrip)
+	}
+
+	values := make([]driver.Value, 0, len(args))
+	for _, arg := range args {
+		values = append(values, arg.Value)
+	}
+	return c.connector.answer(query, values)
+}
+
+type tx struct{}
+
+func (tx) Commit() error   { return nil }
+func (tx) Rollback() error { return nil }
+
+type rows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,869 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Richd0tcom/schedrift/internal/config"
+	"github.com/Richd0tcom/schedrift/internal/models"
+)
+
+// querier runs the extraction queries. It's satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// MySQLExtractor reads a MySQL or MariaDB server's information_schema. MySQL has no schemas
+// within a database, so each database is extracted as a schema of the same name
+type MySQLExtractor struct {
+	conn     *MySQLConnection
+	q        querier
+	tables   *config.TableFilter
+	progress models.ProgressFunc
+}
+
+func NewMySQLExtractor(conn *MySQLConnection) *MySQLExtractor {
+	return &MySQLExtractor{
+		conn:   conn,
+		q:      conn.db,
+		tables: &config.TableFilter{},
+	}
+}
+
+// OnProgress sets a handler that's told how far each extraction has got
+func (e *MySQLExtractor) OnProgress(handler models.ProgressFunc) {
+	e.progress = handler
+}
+
+// systemDatabases hold the server's own catalog rather than user objects
+var systemDatabases = []string{"information_schema", "mysql", "performance_schema", "sys"}
+
+func (e *MySQLExtractor) Extract(ctx context.Context, cfg config.SchemaConfig) (*models.DatabaseSchema, error) {
+	tables, err := cfg.TableFilter()
+	if err != nil {
+		return nil, err
+	}
+	e.tables = tables
+
+	tx, err := e.conn.BeginSnapshot(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	e.q = tx
+	defer func() { e.q = e.conn.db }()
+
+	var dbName sql.NullString
+	if err = e.q.QueryRowContext(ctx, `SELECT DATABASE()`).Scan(&dbName); err != nil {
+		return nil, fmt.Errorf("error getting database name: %w", err)
+	}
+
+	dbSchema := &models.DatabaseSchema{
+		Name:    dbName.String,
+		Schemas: []*models.Schema{},
+	}
+
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT SCHEMA_NAME
+		FROM information_schema.SCHEMATA
+		ORDER BY SCHEMA_NAME
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying databases: %w", err)
+	}
+	defer rows.Close()
+
+	var databases []string
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("error scanning database: %w", err)
+		}
+		databases = append(databases, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating databases: %w", err)
+	}
+	rows.Close()
+
+	// when none are explicitly included, the database named by the connection is extracted,
+	// or every user database if it doesn't name one
+	var schemaNames []string
+	switch {
+	case len(cfg.IncludedSchemas) == 0 && dbName.Valid:
+		if !slices.Contains(cfg.ExcludedSchemas, dbName.String) {
+			schemaNames = append(schemaNames, dbName.String)
+		}
+	case len(cfg.IncludedSchemas) == 0:
+		for _, name := range databases {
+			if !slices.Contains(systemDatabases, name) && !slices.Contains(cfg.ExcludedSchemas, name) {
+				schemaNames = append(schemaNames, name)
+			}
+		}
+	default:
+		for _, name := range cfg.IncludedSchemas {
+			if slices.Contains(cfg.ExcludedSchemas, name) {
+				continue
+			}
+			if !slices.Contains(databases, name) {
+				return nil, fmt.Errorf("database %s not found (MySQL databases are selected with --include)", name)
+			}
+			schemaNames = append(schemaNames, name)
+		}
+	}
+
+	for _, schemaName := range schemaNames {
+		dbSchema.Schemas = append(dbSchema.Schemas, newSchema(schemaName))
+	}
+
+	if err = e.extractSchemas(ctx, dbSchema.Schemas); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error closing snapshot transaction: %w", err)
+	}
+
+	return dbSchema, nil
+}
+
+// schemaPart is an independent piece of a schema's extraction, filling its own fields of the schema
+type schemaPart struct {
+	name    string
+	extract func(e *MySQLExtractor, ctx context.Context, schema *models.Schema) error
+	count   func(schema *models.Schema) int
+}
+
+var schemaParts = []schemaPart{
+	{"tables", (*MySQLExtractor).extractTables, func(s *models.Schema) int { return len(s.Tables) }},
+	{"views", (*MySQLExtractor).extractViews, func(s *models.Schema) int { return len(s.Views) }},
+	{"indexes", (*MySQLExtractor).extractIndexes, func(s *models.Schema) int { return len(s.Indexes) }},
+	{"triggers", (*MySQLExtractor).extractTriggers, func(s *models.Schema) int { return len(s.Triggers) }},
+	{"functions", (*MySQLExtractor).extractRoutines, func(s *models.Schema) int { return len(s.Functions) }},
+}
+
+func newSchema(schemaName string) *models.Schema {
+	return &models.Schema{
+		Name:           schemaName,
+		Tables:         []*models.Table{},
+		Views:          []*models.View{},
+		Triggers:       []*models.Trigger{},
+		Indexes:        []*models.Index{},
+		Functions:      []*models.Function{},
+		Sequences:      []*models.Sequence{},
+		Enums:          []*models.Enum{},
+		Domains:        []*models.Domain{},
+		CompositeTypes: []*models.CompositeType{},
+		Extensions:     []*models.Extension{},
+		Privileges:     []*models.Privilege{},
+		Owners:         []*models.Owner{},
+	}
+}
+
+// countTables counts the tables of the given schemas that the table filter matches
+func (e *MySQLExtractor) countTables(ctx context.Context, schemas []*models.Schema) (int, error) {
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT TABLE_SCHEMA, TABLE_NAME
+		FROM information_schema.TABLES
+		WHERE TABLE_TYPE = 'BASE TABLE'
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("error counting tables: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var schemaName, tableName string
+		if err = rows.Scan(&schemaName, &tableName); err != nil {
+			return 0, fmt.Errorf("error scanning table: %w", err)
+		}
+		if slices.ContainsFunc(schemas, func(s *models.Schema) bool { return s.Name == schemaName }) && e.tables.Match(tableName) {
+			count++
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating tables: %w", err)
+	}
+
+	return count, nil
+}
+
+// extractSchemas fills in every part of the given schemas. Unlike PostgreSQL, MySQL can't share
+// the snapshot with other connections, so the parts are extracted one after another
+func (e *MySQLExtractor) extractSchemas(ctx context.Context, schemas []*models.Schema) error {
+	start := time.Now()
+	tablesDone := 0
+	totals := make(map[string]int)
+
+	// progress is reported in tables, so they're counted up front
+	tables := 0
+	if e.progress != nil {
+		var err error
+		if tables, err = e.countTables(ctx, schemas); err != nil {
+			return err
+		}
+	}
+
+	emit := func(ev models.ProgressEvent) {
+		if e.progress == nil {
+			return
+		}
+		ev.TablesDone = tablesDone
+		ev.Tables = tables
+		ev.Elapsed = time.Since(start)
+		e.progress(ev)
+	}
+
+	emit(models.ProgressEvent{Stage: models.ExtractionStarted})
+
+	for _, schema := range schemas {
+		emit(models.ProgressEvent{Stage: models.SchemaStarted, Schema: schema.Name})
+
+		counts := make(map[string]int)
+		for _, part := range schemaParts {
+			if err := part.extract(e, ctx, schema); err != nil {
+				return fmt.Errorf("error extracting %s of schema %s: %w", part.name, schema.Name, err)
+			}
+
+			count := part.count(schema)
+			if part.name == "tables" {
+				tablesDone += count
+			}
+			counts[part.name] = count
+			totals[part.name] += count
+
+			emit(models.ProgressEvent{
+				Stage:      models.ObjectsExtracted,
+				Schema:     schema.Name,
+				ObjectType: part.name,
+				Count:      count,
+			})
+		}
+
+		emit(models.ProgressEvent{Stage: models.SchemaFinished, Schema: schema.Name, Counts: counts})
+	}
+
+	emit(models.ProgressEvent{Stage: models.ExtractionFinished, Counts: totals})
+	return nil
+}
+
+// extractTables reads every table in the schema, then fills in their columns and constraints
+// with one query each rather than one per table
+func (e *MySQLExtractor) extractTables(ctx context.Context, schema *models.Schema) error {
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			t.TABLE_NAME,
+			t.TABLE_COMMENT,
+			COALESCE(t.ENGINE, ''),
+			COALESCE(ccsa.CHARACTER_SET_NAME, ''),
+			COALESCE(t.TABLE_COLLATION, '')
+		FROM
+			information_schema.TABLES t
+		LEFT JOIN
+			information_schema.COLLATION_CHARACTER_SET_APPLICABILITY ccsa ON ccsa.COLLATION_NAME = t.TABLE_COLLATION
+		WHERE
+			t.TABLE_SCHEMA = ?
+			AND t.TABLE_TYPE = 'BASE TABLE'
+		ORDER BY
+			t.TABLE_NAME
+	`, schema.Name)
+
+	if err != nil {
+		return fmt.Errorf("error querying tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := make(map[string]*models.Table)
+
+	for rows.Next() {
+		table := &models.Table{
+			Schema:      schema.Name,
+			Columns:     []*models.Column{},
+			Constraints: []*models.Constraint{},
+		}
+		if err = rows.Scan(&table.Name, &table.Comment, &table.Engine, &table.Charset, &table.Collation); err != nil {
+			return fmt.Errorf("error scanning table: %w", err)
+		}
+
+		if !e.tables.Match(table.Name) {
+			continue
+		}
+
+		tables[table.Name] = table
+		schema.Tables = append(schema.Tables, table)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating tables: %w", err)
+	}
+	rows.Close()
+
+	if len(tables) == 0 {
+		return nil
+	}
+
+	if err = e.extractColumns(ctx, schema, tables); err != nil {
+		return fmt.Errorf("error extracting columns %w", err)
+	}
+
+	if err = e.extractConstraints(ctx, schema, tables); err != nil {
+		return fmt.Errorf("error extracting constraints %w", err)
+	}
+
+	if err = e.extractChecks(ctx, schema, tables); err != nil {
+		return fmt.Errorf("error extracting check constraints %w", err)
+	}
+
+	return nil
+}
+
+// extractColumns reads the columns of every table in the schema, adding them to the matching entry of tables
+func (e *MySQLExtractor) extractColumns(ctx context.Context, schema *models.Schema, tables map[string]*models.Table) error {
+	// COLUMN_TYPE keeps the modifiers that DATA_TYPE drops, e.g. varchar(50) or int unsigned
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			c.TABLE_NAME,
+			c.COLUMN_NAME,
+			c.COLUMN_TYPE,
+			c.IS_NULLABLE = 'YES',
+			c.COLUMN_DEFAULT,
+			c.EXTRA,
+			COALESCE(c.GENERATION_EXPRESSION, ''),
+			COALESCE(c.COLLATION_NAME, ''),
+			c.COLUMN_COMMENT
+		FROM
+			information_schema.COLUMNS c
+		WHERE
+			c.TABLE_SCHEMA = ?
+		ORDER BY
+			c.TABLE_NAME, c.ORDINAL_POSITION
+	`, schema.Name)
+
+	if err != nil {
+		return fmt.Errorf("error querying columns %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		col := &models.Column{}
+		var tableName, dataType, extra, generated, collation string
+		var defaultValue *string
+		err = rows.Scan(&tableName, &col.Name, &dataType, &col.IsNullable, &defaultValue, &extra, &generated, &collation, &col.Comment)
+		if err != nil {
+			return fmt.Errorf("error scanning column: %w", err)
+		}
+
+		table, ok := tables[tableName]
+		if !ok {
+			continue
+		}
+
+		col.SetDataType(dataType)
+
+		if defaultValue != nil {
+			col.DefaultValue = *defaultValue
+		}
+
+		extra = strings.ToUpper(extra)
+		switch {
+		case strings.Contains(extra, "AUTO_INCREMENT"):
+			// AUTO_INCREMENT only fills in values that aren't supplied, like BY DEFAULT identities
+			col.Identity = "BY DEFAULT"
+		case strings.Contains(extra, "GENERATED") && generated != "":
+			// EXTRA is VIRTUAL GENERATED or STORED GENERATED
+			col.Generated = generated
+			col.Virtual = strings.Contains(extra, "VIRTUAL")
+		}
+
+		// a collation is only recorded when it isn't the table's default
+		if collation != table.Collation {
+			col.Collation = collation
+		}
+
+		table.Columns = append(table.Columns, col)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error scanning columns %w", err)
+	}
+
+	return nil
+}
+
+// fkActions maps REFERENTIAL_CONSTRAINTS rules to their SQL keywords. NO ACTION is the
+// default and is left empty, as is RESTRICT, which InnoDB treats the same way
+var fkActions = map[string]string{
+	"NO ACTION":   "",
+	"RESTRICT":    "",
+	"CASCADE":     "CASCADE",
+	"SET NULL":    "SET NULL",
+	"SET DEFAULT": "SET DEFAULT",
+}
+
+// extractConstraints reads the primary keys, unique constraints and foreign keys of every
+// table in the schema, adding them to the matching entry of tables
+func (e *MySQLExtractor) extractConstraints(ctx context.Context, schema *models.Schema, tables map[string]*models.Table) error {
+	// there's a row per constraint column, so a constraint's rows are consecutive
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			tc.TABLE_NAME,
+			tc.CONSTRAINT_NAME,
+			tc.CONSTRAINT_TYPE,
+			kcu.COLUMN_NAME,
+			COALESCE(kcu.REFERENCED_TABLE_SCHEMA, ''),
+			COALESCE(kcu.REFERENCED_TABLE_NAME, ''),
+			COALESCE(kcu.REFERENCED_COLUMN_NAME, ''),
+			COALESCE(rc.DELETE_RULE, ''),
+			COALESCE(rc.UPDATE_RULE, '')
+		FROM
+			information_schema.TABLE_CONSTRAINTS tc
+		JOIN
+			information_schema.KEY_COLUMN_USAGE kcu ON kcu.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA
+				AND kcu.TABLE_NAME = tc.TABLE_NAME
+				AND kcu.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+		LEFT JOIN
+			information_schema.REFERENTIAL_CONSTRAINTS rc ON rc.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA
+				AND rc.TABLE_NAME = tc.TABLE_NAME
+				AND rc.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+		WHERE
+			tc.TABLE_SCHEMA = ?
+			AND tc.CONSTRAINT_TYPE IN ('PRIMARY KEY', 'UNIQUE', 'FOREIGN KEY')
+		ORDER BY
+			tc.TABLE_NAME, tc.CONSTRAINT_TYPE, tc.CONSTRAINT_NAME, kcu.ORDINAL_POSITION
+	`, schema.Name)
+
+	if err != nil {
+		return fmt.Errorf("error querying constraints: %w", err)
+	}
+	defer rows.Close()
+
+	var constraint *models.Constraint
+	var constraintTable string
+	var refColumns []string
+
+	// finish completes the current constraint once all of its columns have been read
+	finish := func() {
+		if constraint == nil {
+			return
+		}
+		if constraint.Type == models.FOREIGN_KEY {
+			constraint.References += fmt.Sprintf("(%s)", strings.Join(refColumns, ", "))
+		}
+		tables[constraintTable].Constraints = append(tables[constraintTable].Constraints, constraint)
+		constraint = nil
+	}
+
+	for rows.Next() {
+		var tableName, name, conType, column, refSchema, refTable, refColumn, delRule, updRule string
+		err = rows.Scan(&tableName, &name, &conType, &column, &refSchema, &refTable, &refColumn, &delRule, &updRule)
+		if err != nil {
+			return fmt.Errorf("error scanning constraint: %w", err)
+		}
+
+		table, ok := tables[tableName]
+		if !ok {
+			continue
+		}
+
+		// primary keys are always called PRIMARY, so they're given the name PostgreSQL would
+		// use, which is also what parsed DDL defaults to
+		if conType == string(models.PRIMARY_KEY) {
+			name = tableName + "_pkey"
+		}
+
+		if constraint == nil || constraintTable != tableName || constraint.Name != name {
+			finish()
+
+			constraint = &models.Constraint{
+				Name: name,
+				Type: models.ConstraintType(conType),
+			}
+			constraintTable = tableName
+			refColumns = nil
+
+			if constraint.Type == models.FOREIGN_KEY {
+				// only qualify the referenced table when it lives in another database, matching parsed DDL
+				constraint.References = refTable
+				if refSchema != table.Schema {
+					constraint.References = refSchema + "." + refTable
+				}
+				constraint.OnDelete = fkActions[delRule]
+				constraint.OnUpdate = fkActions[updRule]
+			}
+		}
+
+		constraint.Columns = append(constraint.Columns, column)
+		if refColumn != "" {
+			refColumns = append(refColumns, refColumn)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating constraints: %w", err)
+	}
+	finish()
+
+	return nil
+}
+
+// hasInformationSchemaColumn reports whether the server's information_schema has the given
+// column, which depends on the MySQL or MariaDB version
+func (e *MySQLExtractor) hasInformationSchemaColumn(ctx context.Context, table, column string) (bool, error) {
+	var count int
+	err := e.q.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = 'information_schema' AND TABLE_NAME = ? AND COLUMN_NAME = ?
+	`, table, column).Scan(&count)
+
+	if err != nil {
+		return false, fmt.Errorf("error checking for information_schema.%s.%s: %w", table, column, err)
+	}
+	return count > 0, nil
+}
+
+// extractChecks reads the CHECK constraints of every table in the schema. Servers older than
+// MySQL 8.0.16 and MariaDB 10.2 parse CHECK constraints but don't keep them
+func (e *MySQLExtractor) extractChecks(ctx context.Context, schema *models.Schema, tables map[string]*models.Table) error {
+	supported, err := e.hasInformationSchemaColumn(ctx, "CHECK_CONSTRAINTS", "CHECK_CLAUSE")
+	if err != nil || !supported {
+		return err
+	}
+
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			tc.TABLE_NAME,
+			tc.CONSTRAINT_NAME,
+			cc.CHECK_CLAUSE
+		FROM
+			information_schema.TABLE_CONSTRAINTS tc
+		JOIN
+			information_schema.CHECK_CONSTRAINTS cc ON cc.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA
+				AND cc.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+		WHERE
+			tc.TABLE_SCHEMA = ?
+			AND tc.CONSTRAINT_TYPE = 'CHECK'
+		ORDER BY
+			tc.TABLE_NAME, tc.CONSTRAINT_NAME
+	`, schema.Name)
+
+	if err != nil {
+		return fmt.Errorf("error querying check constraints: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		constraint := &models.Constraint{Type: models.CHECK}
+		var tableName string
+		if err = rows.Scan(&tableName, &constraint.Name, &constraint.CheckExpr); err != nil {
+			return fmt.Errorf("error scanning check constraint: %w", err)
+		}
+
+		table, ok := tables[tableName]
+		if !ok {
+			continue
+		}
+		table.Constraints = append(table.Constraints, constraint)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating check constraints: %w", err)
+	}
+
+	return nil
+}
+
+func (e *MySQLExtractor) extractViews(ctx context.Context, schema *models.Schema) error {
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			v.TABLE_NAME,
+			v.VIEW_DEFINITION
+		FROM
+			information_schema.VIEWS v
+		WHERE
+			v.TABLE_SCHEMA = ?
+		ORDER BY
+			v.TABLE_NAME
+	`, schema.Name)
+
+	if err != nil {
+		return fmt.Errorf("error querying views: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		view := &models.View{
+			Schema: schema.Name,
+		}
+		if err = rows.Scan(&view.Name, &view.Definition); err != nil {
+			return fmt.Errorf("error scanning view: %w", err)
+		}
+		schema.Views = append(schema.Views, view)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating views: %w", err)
+	}
+
+	return nil
+}
+
+func (e *MySQLExtractor) extractIndexes(ctx context.Context, schema *models.Schema) error {
+	// functional key parts (MySQL 8.0.13) have no column, only an expression
+	expression := "NULL"
+	supported, err := e.hasInformationSchemaColumn(ctx, "STATISTICS", "EXPRESSION")
+	if err != nil {
+		return err
+	}
+	if supported {
+		expression = "s.EXPRESSION"
+	}
+
+	// indexes backing primary keys and unique constraints are already described by the
+	// table's constraints. There's a row per key part, so an index's rows are consecutive
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			s.TABLE_NAME,
+			s.INDEX_NAME,
+			s.NON_UNIQUE = 0,
+			s.INDEX_TYPE,
+			COALESCE(s.COLUMN_NAME, `+expression+`, ''),
+			s.SUB_PART,
+			COALESCE(s.COLLATION, '')
+		FROM
+			information_schema.STATISTICS s
+		WHERE
+			s.TABLE_SCHEMA = ?
+			AND NOT EXISTS (
+				SELECT 1
+				FROM information_schema.TABLE_CONSTRAINTS tc
+				WHERE tc.TABLE_SCHEMA = s.TABLE_SCHEMA
+					AND tc.TABLE_NAME = s.TABLE_NAME
+					AND tc.CONSTRAINT_NAME = s.INDEX_NAME
+					AND tc.CONSTRAINT_TYPE IN ('PRIMARY KEY', 'UNIQUE')
+			)
+		ORDER BY
+			s.TABLE_NAME, s.INDEX_NAME, s.SEQ_IN_INDEX
+	`, schema.Name)
+
+	if err != nil {
+		return fmt.Errorf("error querying indexes: %w", err)
+	}
+	defer rows.Close()
+
+	var index *models.Index
+	for rows.Next() {
+		var tableName, name, method, column, collation string
+		var unique bool
+		var subPart *int
+		if err = rows.Scan(&tableName, &name, &unique, &method, &column, &subPart, &collation); err != nil {
+			return fmt.Errorf("error scanning index: %w", err)
+		}
+
+		if !e.tables.Match(tableName) {
+			continue
+		}
+
+		if index == nil || index.Table != tableName || index.Name != name {
+			index = &models.Index{
+				Name:     name,
+				Schema:   schema.Name,
+				Table:    tableName,
+				IsUnique: unique,
+				IsValid:  true,
+				Method:   strings.ToLower(method),
+			}
+			schema.Indexes = append(schema.Indexes, index)
+		}
+
+		// a prefix index only covers the first SUB_PART characters of the column
+		if subPart != nil {
+			column = fmt.Sprintf("%s(%d)", column, *subPart)
+		}
+		index.Columns = append(index.Columns, column)
+
+		sortOrder := ""
+		if collation == "D" {
+			sortOrder = "DESC"
+		}
+		index.SortOrders = append(index.SortOrders, sortOrder)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating indexes: %w", err)
+	}
+
+	return nil
+}
+
+func (e *MySQLExtractor) extractTriggers(ctx context.Context, schema *models.Schema) error {
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			t.TRIGGER_NAME,
+			t.EVENT_OBJECT_TABLE,
+			t.EVENT_MANIPULATION,
+			t.ACTION_TIMING,
+			t.ACTION_ORIENTATION,
+			t.ACTION_STATEMENT
+		FROM
+			information_schema.TRIGGERS t
+		WHERE
+			t.TRIGGER_SCHEMA = ?
+		ORDER BY
+			t.EVENT_OBJECT_TABLE, t.TRIGGER_NAME
+	`, schema.Name)
+
+	if err != nil {
+		return fmt.Errorf("error querying triggers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		trigger := &models.Trigger{
+			Schema: schema.Name,
+			// MySQL triggers can't be disabled
+			State: "ENABLED",
+		}
+		var event string
+		err = rows.Scan(&trigger.Name, &trigger.Table, &event, &trigger.Timing, &trigger.ForEach, &trigger.Statement)
+		if err != nil {
+			return fmt.Errorf("error scanning trigger: %w", err)
+		}
+
+		if !e.tables.Match(trigger.Table) {
+			continue
+		}
+
+		// a MySQL trigger fires on a single event
+		trigger.Events = []string{event}
+		schema.Triggers = append(schema.Triggers, trigger)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating triggers: %w", err)
+	}
+
+	return nil
+}
+
+// extractRoutines reads the schema's stored functions and procedures, then fills in their arguments
+func (e *MySQLExtractor) extractRoutines(ctx context.Context, schema *models.Schema) error {
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			r.ROUTINE_NAME,
+			r.ROUTINE_TYPE,
+			COALESCE(r.DTD_IDENTIFIER, ''),
+			COALESCE(r.ROUTINE_DEFINITION, ''),
+			r.IS_DETERMINISTIC = 'YES',
+			r.SECURITY_TYPE = 'DEFINER'
+		FROM
+			information_schema.ROUTINES r
+		WHERE
+			r.ROUTINE_SCHEMA = ?
+		ORDER BY
+			r.ROUTINE_NAME
+	`, schema.Name)
+
+	if err != nil {
+		return fmt.Errorf("error querying routines: %w", err)
+	}
+	defer rows.Close()
+
+	routines := make(map[string]*models.Function)
+
+	for rows.Next() {
+		function := &models.Function{
+			Schema:     schema.Name,
+			Language:   "SQL",
+			Volatility: "VOLATILE",
+		}
+		var deterministic bool
+		err = rows.Scan(&function.Name, &function.Kind, &function.ReturnType, &function.Body, &deterministic, &function.SecurityDefiner)
+		if err != nil {
+			return fmt.Errorf("error scanning routine: %w", err)
+		}
+
+		// DETERMINISTIC promises the same result for the same arguments, as IMMUTABLE does
+		if deterministic {
+			function.Volatility = "IMMUTABLE"
+		}
+
+		routines[function.Kind+" "+function.Name] = function
+		schema.Functions = append(schema.Functions, function)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating routines: %w", err)
+	}
+	rows.Close()
+
+	if len(routines) == 0 {
+		return nil
+	}
+
+	return e.extractRoutineArguments(ctx, schema, routines)
+}
+
+// extractRoutineArguments sets the arguments of the routines keyed by kind and name
+func (e *MySQLExtractor) extractRoutineArguments(ctx context.Context, schema *models.Schema, routines map[string]*models.Function) error {
+	// position 0 describes a function's return value rather than an argument
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			p.ROUTINE_TYPE,
+			p.SPECIFIC_NAME,
+			COALESCE(p.PARAMETER_MODE, ''),
+			COALESCE(p.PARAMETER_NAME, ''),
+			p.DTD_IDENTIFIER
+		FROM
+			information_schema.PARAMETERS p
+		WHERE
+			p.SPECIFIC_SCHEMA = ?
+			AND p.ORDINAL_POSITION > 0
+		ORDER BY
+			p.SPECIFIC_NAME, p.ORDINAL_POSITION
+	`, schema.Name)
+
+	if err != nil {
+		return fmt.Errorf("error querying routine arguments: %w", err)
+	}
+	defer rows.Close()
+
+	arguments := make(map[string][]string)
+
+	for rows.Next() {
+		var kind, routine, mode, name, dataType string
+		if err = rows.Scan(&kind, &routine, &mode, &name, &dataType); err != nil {
+			return fmt.Errorf("error scanning routine argument: %w", err)
+		}
+
+		// IN is the default mode, and function arguments have no mode at all
+		argument := name + " " + dataType
+		if mode != "" && mode != "IN" {
+			argument = mode + " " + argument
+		}
+
+		key := kind + " " + routine
+		arguments[key] = append(arguments[key], argument)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating routine arguments: %w", err)
+	}
+
+	for key, args := range arguments {
+		if function, ok := routines[key]; ok {
+			function.Arguments = strings.Join(args, ", ")
+		}
+	}
+
+	return nil
+}
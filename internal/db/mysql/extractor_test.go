@@ -0,0 +1,337 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Richd0tcom/schedrift/internal/config"
+	"github.com/Richd0tcom/schedrift/internal/models"
+)
+
+// catalogQueries answers the extraction queries for a database "app" holding a users and a
+// posts table, as a MySQL 8.0 server would
+func catalogQueries(database driver.Value) []fakeQuery {
+	return []fakeQuery{
+		{match: "SELECT VERSION()", columns: []string{"VERSION()"}, rows: [][]driver.Value{{"8.0.36"}}},
+		{match: "SELECT DATABASE()", columns: []string{"DATABASE()"}, rows: [][]driver.Value{{database}}},
+		{
+			match:   "information_schema.SCHEMATA",
+			columns: []string{"SCHEMA_NAME"},
+			rows:    [][]driver.Value{{"app"}, {"information_schema"}, {"mysql"}, {"performance_schema"}, {"sys"}},
+		},
+		{
+			match:   "SELECT COUNT(*)",
+			args:    []driver.Value{"CHECK_CONSTRAINTS", "CHECK_CLAUSE"},
+			columns: []string{"COUNT(*)"},
+			rows:    [][]driver.Value{{int64(1)}},
+		},
+		{
+			match:   "SELECT COUNT(*)",
+			args:    []driver.Value{"STATISTICS", "EXPRESSION"},
+			columns: []string{"COUNT(*)"},
+			rows:    [][]driver.Value{{int64(1)}},
+		},
+		{
+			match:   "SELECT TABLE_SCHEMA, TABLE_NAME",
+			columns: []string{"TABLE_SCHEMA", "TABLE_NAME"},
+			rows:    [][]driver.Value{{"app", "posts"}, {"app", "users"}, {"mysql", "user"}},
+		},
+		{
+			match:   "information_schema.TABLES t",
+			args:    []driver.Value{"app"},
+			columns: []string{"TABLE_NAME", "TABLE_COMMENT", "ENGINE", "CHARACTER_SET_NAME", "TABLE_COLLATION"},
+			rows: [][]driver.Value{
+				{"posts", "", "InnoDB", "utf8mb4", "utf8mb4_0900_ai_ci"},
+				{"users", "people", "InnoDB", "utf8mb4", "utf8mb4_0900_ai_ci"},
+			},
+		},
+		{
+			match:   "information_schema.COLUMNS c",
+			args:    []driver.Value{"app"},
+			columns: []string{"TABLE_NAME", "COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT", "EXTRA", "GENERATION_EXPRESSION", "COLLATION_NAME", "COLUMN_COMMENT"},
+			rows: [][]driver.Value{
+				{"posts", "id", "int", int64(0), nil, "auto_increment", "", "", ""},
+				{"posts", "user_id", "bigint unsigned", int64(1), nil, "", "", "", ""},
+				{"posts", "title", "varchar(200)", int64(0), "untitled", "", "", "utf8mb4_0900_ai_ci", ""},
+				{"posts", "slug", "varchar(200)", int64(1), nil, "VIRTUAL GENERATED", "lower(`title`)", "utf8mb4_0900_ai_ci", ""},
+				{"posts", "price", "decimal(10,2)", int64(0), "0.00", "", "", "", ""},
+				{"posts", "gross", "decimal(10,2)", int64(1), nil, "STORED GENERATED", "(`price` * 1.2)", "", ""},
+				{"users", "id", "bigint unsigned", int64(0), nil, "auto_increment", "", "", ""},
+				{"users", "email", "varchar(255)", int64(0), nil, "", "", "utf8mb4_bin", "login"},
+			},
+		},
+		{
+			match:   "KEY_COLUMN_USAGE",
+			args:    []driver.Value{"app"},
+			columns: []string{"TABLE_NAME", "CONSTRAINT_NAME", "CONSTRAINT_TYPE", "COLUMN_NAME", "REFERENCED_TABLE_SCHEMA", "REFERENCED_TABLE_NAME", "REFERENCED_COLUMN_NAME", "DELETE_RULE", "UPDATE_RULE"},
+			rows: [][]driver.Value{
+				{"posts", "posts_user_fk", "FOREIGN KEY", "user_id", "app", "users", "id", "CASCADE", "NO ACTION"},
+				{"posts", "PRIMARY", "PRIMARY KEY", "id", "", "", "", "", ""},
+				{"users", "PRIMARY", "PRIMARY KEY", "id", "", "", "", "", ""},
+				{"users", "users_email_uq", "UNIQUE", "email", "", "", "", "", ""},
+			},
+		},
+		{
+			match:   "information_schema.CHECK_CONSTRAINTS cc",
+			args:    []driver.Value{"app"},
+			columns: []string{"TABLE_NAME", "CONSTRAINT_NAME", "CHECK_CLAUSE"},
+			rows:    [][]driver.Value{{"posts", "posts_chk_1", "(`price` >= 0)"}},
+		},
+		{
+			match:   "information_schema.VIEWS",
+			args:    []driver.Value{"app"},
+			columns: []string{"TABLE_NAME", "VIEW_DEFINITION"},
+			rows:    [][]driver.Value{{"cheap_posts", "select `app`.`posts`.`id` AS `id` from `app`.`posts` where (`app`.`posts`.`price` < 10)"}},
+		},
+		{
+			match:   "information_schema.STATISTICS s",
+			args:    []driver.Value{"app"},
+			columns: []string{"TABLE_NAME", "INDEX_NAME", "NON_UNIQUE = 0", "INDEX_TYPE", "COLUMN_NAME", "SUB_PART", "COLLATION"},
+			rows: [][]driver.Value{
+				{"posts", "idx_title", int64(1), "BTREE", "title", int64(10), "A"},
+				{"posts", "idx_user_price", int64(0), "BTREE", "user_id", nil, "A"},
+				{"posts", "idx_user_price", int64(0), "BTREE", "price", nil, "D"},
+			},
+		},
+		{
+			match:   "information_schema.TRIGGERS",
+			args:    []driver.Value{"app"},
+			columns: []string{"TRIGGER_NAME", "EVENT_OBJECT_TABLE", "EVENT_MANIPULATION", "ACTION_TIMING", "ACTION_ORIENTATION", "ACTION_STATEMENT"},
+			rows:    [][]driver.Value{{"posts_bi", "posts", "INSERT", "BEFORE", "ROW", "SET NEW.title = TRIM(NEW.title)"}},
+		},
+		{
+			match:   "information_schema.ROUTINES",
+			args:    []driver.Value{"app"},
+			columns: []string{"ROUTINE_NAME", "ROUTINE_TYPE", "DTD_IDENTIFIER", "ROUTINE_DEFINITION", "IS_DETERMINISTIC", "SECURITY_TYPE"},
+			rows: [][]driver.Value{
+				{"with_tax", "FUNCTION", "decimal(10,2)", "RETURN price * 1.2", int64(1), int64(0)},
+				{"archive", "PROCEDURE", "", "BEGIN END", int64(0), int64(1)},
+			},
+		},
+		{
+			match:   "information_schema.PARAMETERS",
+			args:    []driver.Value{"app"},
+			columns: []string{"ROUTINE_TYPE", "SPECIFIC_NAME", "PARAMETER_MODE", "PARAMETER_NAME", "DTD_IDENTIFIER"},
+			rows: [][]driver.Value{
+				{"PROCEDURE", "archive", "IN", "before_id", "int"},
+				{"PROCEDURE", "archive", "OUT", "archived", "int"},
+				{"FUNCTION", "with_tax", "", "price", "decimal(10,2)"},
+			},
+		},
+	}
+}
+
+func extractFake(t *testing.T, cfg config.SchemaConfig, queries []fakeQuery) *models.DatabaseSchema {
+	t.Helper()
+
+	conn := NewConnectionFromDB(openFake(queries...), config.DatabaseConfig{})
+	defer conn.Close()
+
+	dbSchema, err := NewMySQLExtractor(conn).Extract(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("extraction failed: %v", err)
+	}
+	return dbSchema
+}
+
+func findTable(t *testing.T, schema *models.Schema, name string) *models.Table {
+	t.Helper()
+	for _, table := range schema.Tables {
+		if table.Name == name {
+			return table
+		}
+	}
+	t.Fatalf("table %s wasn't extracted", name)
+	return nil
+}
+
+func findColumn(t *testing.T, table *models.Table, name string) *models.Column {
+	t.Helper()
+	for _, col := range table.Columns {
+		if col.Name == name {
+			return col
+		}
+	}
+	t.Fatalf("column %s.%s wasn't extracted", table.Name, name)
+	return nil
+}
+
+func TestExtractDefaultsToConnectedDatabase(t *testing.T) {
+	dbSchema := extractFake(t, config.SchemaConfig{}, catalogQueries("app"))
+
+	if dbSchema.Name != "app" || len(dbSchema.Schemas) != 1 || dbSchema.Schemas[0].Name != "app" {
+		t.Fatalf("expected only the app database to be extracted, got %+v", dbSchema.Schemas)
+	}
+}
+
+func TestExtractAllUserDatabasesWithoutDefault(t *testing.T) {
+	// an empty shop database sits alongside app
+	queries := append([]fakeQuery{
+		{
+			match:   "information_schema.SCHEMATA",
+			columns: []string{"SCHEMA_NAME"},
+			rows:    [][]driver.Value{{"app"}, {"information_schema"}, {"mysql"}, {"shop"}, {"sys"}},
+		},
+		{match: "", args: []driver.Value{"shop"}},
+	}, catalogQueries(nil)...)
+
+	dbSchema := extractFake(t, config.SchemaConfig{}, queries)
+
+	// the system databases are left out
+	var names []string
+	for _, schema := range dbSchema.Schemas {
+		names = append(names, schema.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"app", "shop"}) {
+		t.Fatalf("expected the app and shop databases to be extracted, got %v", names)
+	}
+}
+
+func TestExtractMissingDatabase(t *testing.T) {
+	conn := NewConnectionFromDB(openFake(catalogQueries("app")...), config.DatabaseConfig{})
+	defer conn.Close()
+
+	_, err := NewMySQLExtractor(conn).Extract(context.Background(), config.SchemaConfig{IncludedSchemas: []string{"public"}})
+	if err == nil || !strings.Contains(err.Error(), "database public not found") {
+		t.Fatalf("expected a missing database error, got %v", err)
+	}
+}
+
+func TestExtractTables(t *testing.T) {
+	schema := extractFake(t, config.SchemaConfig{IncludedSchemas: []string{"app"}}, catalogQueries("app")).Schemas[0]
+
+	users := findTable(t, schema, "users")
+	if users.Engine != "InnoDB" || users.Charset != "utf8mb4" || users.Collation != "utf8mb4_0900_ai_ci" || users.Comment != "people" {
+		t.Errorf("unexpected table options %+v", users)
+	}
+
+	id := findColumn(t, users, "id")
+	if id.DataType != "bigint unsigned" || id.IsNullable || id.Identity != "BY DEFAULT" {
+		t.Errorf("expected an auto_increment id, got %+v", id)
+	}
+
+	// only a collation other than the table's default is kept
+	if email := findColumn(t, users, "email"); email.Collation != "utf8mb4_bin" || email.Comment != "login" {
+		t.Errorf("expected email to keep its own collation, got %+v", email)
+	}
+
+	posts := findTable(t, schema, "posts")
+	if title := findColumn(t, posts, "title"); title.Collation != "" || title.DefaultValue != "untitled" || title.Length != 200 {
+		t.Errorf("unexpected title column %+v", title)
+	}
+	if price := findColumn(t, posts, "price"); price.Precision != 10 || price.Scale != 2 {
+		t.Errorf("expected price to be decimal(10,2), got %+v", price)
+	}
+	if slug := findColumn(t, posts, "slug"); slug.Generated != "lower(`title`)" || !slug.Virtual {
+		t.Errorf("expected slug to be a virtual generated column, got %+v", slug)
+	}
+	if gross := findColumn(t, posts, "gross"); gross.Generated != "(`price` * 1.2)" || gross.Virtual {
+		t.Errorf("expected gross to be a stored generated column, got %+v", gross)
+	}
+}
+
+func TestExtractConstraints(t *testing.T) {
+	schema := extractFake(t, config.SchemaConfig{IncludedSchemas: []string{"app"}}, catalogQueries("app")).Schemas[0]
+
+	expected := map[string][]*models.Constraint{
+		"posts": {
+			{Name: "posts_user_fk", Type: models.FOREIGN_KEY, Columns: []string{"user_id"}, References: "users(id)", OnDelete: "CASCADE"},
+			{Name: "posts_pkey", Type: models.PRIMARY_KEY, Columns: []string{"id"}},
+			{Name: "posts_chk_1", Type: models.CHECK, CheckExpr: "(`price` >= 0)"},
+		},
+		"users": {
+			{Name: "users_pkey", Type: models.PRIMARY_KEY, Columns: []string{"id"}},
+			{Name: "users_email_uq", Type: models.UNIQUE, Columns: []string{"email"}},
+		},
+	}
+
+	for tableName, constraints := range expected {
+		table := findTable(t, schema, tableName)
+		if !reflect.DeepEqual(table.Constraints, constraints) {
+			t.Errorf("unexpected constraints on %s:", tableName)
+			for _, con := range table.Constraints {
+				t.Logf("  %+v", con)
+			}
+		}
+	}
+}
+
+func TestExtractIndexesTriggersAndRoutines(t *testing.T) {
+	schema := extractFake(t, config.SchemaConfig{IncludedSchemas: []string{"app"}}, catalogQueries("app")).Schemas[0]
+
+	expectedIndexes := []*models.Index{
+		{Name: "idx_title", Schema: "app", Table: "posts", Columns: []string{"title(10)"}, IsUnique: true, IsValid: true, Method: "btree", SortOrders: []string{""}},
+		{Name: "idx_user_price", Schema: "app", Table: "posts", Columns: []string{"user_id", "price"}, IsValid: true, Method: "btree", SortOrders: []string{"", "DESC"}},
+	}
+	if !reflect.DeepEqual(schema.Indexes, expectedIndexes) {
+		t.Errorf("unexpected indexes:")
+		for _, index := range schema.Indexes {
+			t.Logf("  %+v", index)
+		}
+	}
+
+	if len(schema.Views) != 1 || schema.Views[0].Name != "cheap_posts" {
+		t.Errorf("expected the cheap_posts view, got %+v", schema.Views)
+	}
+
+	expectedTrigger := &models.Trigger{
+		Name:      "posts_bi",
+		Schema:    "app",
+		Table:     "posts",
+		Events:    []string{"INSERT"},
+		Timing:    "BEFORE",
+		ForEach:   "ROW",
+		State:     "ENABLED",
+		Statement: "SET NEW.title = TRIM(NEW.title)",
+	}
+	if len(schema.Triggers) != 1 || !reflect.DeepEqual(schema.Triggers[0], expectedTrigger) {
+		t.Errorf("unexpected triggers %+v", schema.Triggers)
+	}
+
+	routines := make(map[string]*models.Function)
+	for _, function := range schema.Functions {
+		routines[function.Name] = function
+	}
+	if fn := routines["with_tax"]; fn == nil || fn.Arguments != "price decimal(10,2)" || fn.ReturnType != "decimal(10,2)" || fn.Volatility != "IMMUTABLE" {
+		t.Errorf("unexpected function %+v", fn)
+	}
+	if proc := routines["archive"]; proc == nil || proc.Arguments != "before_id int, OUT archived int" || !proc.SecurityDefiner {
+		t.Errorf("unexpected procedure %+v", proc)
+	}
+}
+
+func TestExtractTableFilter(t *testing.T) {
+	cfg := config.SchemaConfig{IncludedSchemas: []string{"app"}, ExcludedTables: []string{"posts"}}
+	schema := extractFake(t, cfg, catalogQueries("app")).Schemas[0]
+
+	if len(schema.Tables) != 1 || schema.Tables[0].Name != "users" {
+		t.Errorf("expected posts to be filtered out, got %+v", schema.Tables)
+	}
+	if len(schema.Indexes) != 0 || len(schema.Triggers) != 0 {
+		t.Errorf("expected the indexes and triggers of posts to be filtered out, got %+v and %+v", schema.Indexes, schema.Triggers)
+	}
+}
+
+func TestExtractProgress(t *testing.T) {
+	conn := NewConnectionFromDB(openFake(catalogQueries("app")...), config.DatabaseConfig{})
+	defer conn.Close()
+
+	e := NewMySQLExtractor(conn)
+	var events []models.ProgressEvent
+	e.OnProgress(func(ev models.ProgressEvent) { events = append(events, ev) })
+
+	if _, err := e.Extract(context.Background(), config.SchemaConfig{}); err != nil {
+		t.Fatalf("extraction failed: %v", err)
+	}
+
+	// only the tables of the extracted databases are counted
+	if first := events[0]; first.Stage != models.ExtractionStarted || first.Tables != 2 || first.TablesDone != 0 {
+		t.Errorf("unexpected first event: %+v", first)
+	}
+	if last := events[len(events)-1]; last.Stage != models.ExtractionFinished || last.TablesDone != 2 || last.Counts["tables"] != 2 {
+		t.Errorf("unexpected last event: %+v", last)
+	}
+}
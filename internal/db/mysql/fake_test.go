@@ -0,0 +1,34 @@
+package mysql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+
+	"github.com/Richd0tcom/schedrift/internal/db/dbtest"
+)
+
+// fakeQuery is a canned answer to any query containing match, and taking args when they're given
+type fakeQuery struct {
+	match   string
+	args    []driver.Value
+	columns []string
+	rows    [][]driver.Value
+}
+
+// openFake returns a database backed by the given queries
+func openFake(queries ...fakeQuery) *sql.DB {
+	canned := make([]dbtest.Query, 0, len(queries))
+	for _, q := range queries {
+		query := dbtest.Query{Match: q.match, Columns: q.columns, Rows: q.rows}
+		if q.args != nil {
+			query.Filter = func(args []driver.Value) ([][]driver.Value, bool, error) {
+				return q.rows, reflect.DeepEqual(q.args, args), nil
+			}
+		}
+		canned = append(canned, query)
+	}
+
+	db, _ := dbtest.Open(0, canned...)
+	return db
+}
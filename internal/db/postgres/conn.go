@@ -1,20 +1,25 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
 
 	"github.com/Richd0tcom/schedrift/internal/config"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type PGConnection struct {
 	db *sql.DB
 	DriverName string
+
+	statementTimeout time.Duration
+	lockTimeout      time.Duration
+	workers          int
 }
 
-func NewConnection(cfg config.DatabaseConfig) (*PGConnection, error) {
+func NewConnection(ctx context.Context, cfg config.DatabaseConfig) (*PGConnection, error) {
 	var connStr string
 	if cfg.Url != "" {
 		connStr = cfg.Url
@@ -31,15 +36,28 @@ func NewConnection(cfg config.DatabaseConfig) (*PGConnection, error) {
 		return nil, fmt.Errorf("failed to open database connection: %w", err)
 	}
 
-	db.SetMaxOpenConns(5)
-	db.SetMaxIdleConns(5)
+	// one connection holds the extraction's snapshot while the workers query in parallel
+	workers := max(cfg.Workers, 1)
+	db.SetMaxOpenConns(workers + 1)
+	db.SetMaxIdleConns(workers + 1)
 	db.SetConnMaxIdleTime(time.Hour)
 
-	if err := db.Ping(); err != nil {
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &PGConnection{db: db}, nil
+	return NewConnectionFromDB(db, cfg), nil
+}
+
+// NewConnectionFromDB wraps an already opened database, e.g. one backed by a fake driver
+func NewConnectionFromDB(db *sql.DB, cfg config.DatabaseConfig) *PGConnection {
+	return &PGConnection{
+		db:               db,
+		statementTimeout: cfg.StatementTimeout,
+		lockTimeout:      cfg.LockTimeout,
+		workers:          max(cfg.Workers, 1),
+	}
 }
 
 
@@ -51,22 +69,67 @@ func (c *PGConnection) DB() *sql.DB {
 	return c.db
 }
 
-func (c *PGConnection) Query(query string, args ...any) (*sql.Rows, error) {
-	return c.db.Query(query, args...)
+func (c *PGConnection) Query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return c.db.QueryContext(ctx, query, args...)
 }
 
-func (c *PGConnection) QueryRow(query string, args ...any) *sql.Row {
-	return c.db.QueryRow(query, args...)
+func (c *PGConnection) QueryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	return c.db.QueryRowContext(ctx, query, args...)
 }
 
 // Exec executes a query without returning any rows
-func (c *PGConnection) Exec(query string, args ...any) (sql.Result, error) {
-	return c.db.Exec(query, args...)
+func (c *PGConnection) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return c.db.ExecContext(ctx, query, args...)
+}
+
+// BeginSnapshot starts a REPEATABLE READ READ ONLY transaction, so that every query in it sees
+// the database as it was when the transaction's first query ran. When snapshotID is set, the
+// transaction imports that snapshot (see ExportSnapshot) and sees exactly what its exporter does.
+// The connection's statement and lock timeouts apply to every query in the transaction.
+func (c *PGConnection) BeginSnapshot(ctx context.Context, snapshotID string) (*sql.Tx, error) {
+	tx, err := c.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if snapshotID != "" {
+		// SET TRANSACTION doesn't accept parameters, so the id is quoted instead
+		if _, err := tx.ExecContext(ctx, "SET TRANSACTION SNAPSHOT "+pq.QuoteLiteral(snapshotID)); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to import snapshot %s: %w", snapshotID, err)
+		}
+	}
+
+	// SET LOCAL keeps the timeouts from outliving the transaction on the pooled connection
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", c.statementTimeout.Milliseconds())); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to set statement_timeout: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL lock_timeout = %d", c.lockTimeout.Milliseconds())); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to set lock_timeout: %w", err)
+	}
+
+	return tx, nil
+}
+
+// ExportSnapshot exports the snapshot of a transaction started with BeginSnapshot. The
+// snapshot can be imported by other transactions for as long as tx stays open.
+func (c *PGConnection) ExportSnapshot(ctx context.Context, tx *sql.Tx) (string, error) {
+	var snapshotID string
+	if err := tx.QueryRowContext(ctx, "SELECT pg_export_snapshot()").Scan(&snapshotID); err != nil {
+		return "", fmt.Errorf("failed to export snapshot: %w", err)
+	}
+	return snapshotID, nil
 }
 
-func (c *PGConnection) GetVersion() (string, error) {
+func (c *PGConnection) GetVersion(ctx context.Context) (string, error) {
 	var version string
-	err := c.db.QueryRow("SELECT version()").Scan(&version)
+	err := c.db.QueryRowContext(ctx, "SELECT version()").Scan(&version)
 
 	if err != nil {
 		return "", fmt.Errorf("failed to get PostgreSQL version: %w", err)
@@ -1,23 +1,66 @@
 package postgres
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/Richd0tcom/schedrift/internal/config"
 	"github.com/Richd0tcom/schedrift/internal/models"
+	"github.com/lib/pq"
 )
 
+// querier runs the extraction queries. It's satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
 type PGExtractor struct {
-	conn *PGConnection
+	conn     *PGConnection
+	q        querier
+	tables   *config.TableFilter
+	progress models.ProgressFunc
 }
 
 func NewPGExtractor(conn *PGConnection) *PGExtractor {
-	return &PGExtractor{conn: conn}
+	return &PGExtractor{
+		conn:   conn,
+		q:      conn.db,
+		tables: &config.TableFilter{},
+	}
+}
+
+// OnProgress sets a handler that's told how far each extraction has got
+func (e *PGExtractor) OnProgress(handler models.ProgressFunc) {
+	e.progress = handler
 }
 
-func (e *PGExtractor) Extract(incluedSchemas,  excludedSchemas []string) (*models.DatabaseSchema, error) {
+func (e *PGExtractor) Extract(ctx context.Context, cfg config.SchemaConfig) (*models.DatabaseSchema, error) {
+
+	tables, err := cfg.TableFilter()
+	if err != nil {
+		return nil, err
+	}
+	e.tables = tables
+
+	// every query runs in one snapshot so that concurrent DDL can't produce a torn schema
+	tx, err := e.conn.BeginSnapshot(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	e.q = tx
+	defer func() { e.q = e.conn.db }()
 
 	var dbName string
-	err:= e.conn.QueryRow(`SELECT current_database()`).Scan(&dbName)
+	err = e.q.QueryRowContext(ctx, `SELECT current_database()`).Scan(&dbName)
 
 	if err != nil {
 		return nil, fmt.Errorf("error getting database name: %w", err)
@@ -28,16 +71,15 @@ func (e *PGExtractor) Extract(incluedSchemas,  excludedSchemas []string) (*model
 		Schemas: []*models.Schema{},
 	}
 
-	// Build schema filter
-	// schemaFilter := e.buildSchemaFilter(includedSchemas, excludedSchemas)
-	schemaFilter:= ""
-
-	rows, err:= e.conn.Query(`SELECT schema_name
+	// every schema is extracted when none are explicitly included
+	rows, err:= e.q.QueryContext(ctx, `SELECT schema_name
 		FROM information_schema.schemata
 		WHERE schema_name NOT IN ('information_schema', 'pg_catalog', 'pg_toast') 
-		AND ` + schemaFilter + `
+		AND schema_name NOT LIKE 'pg\_temp\_%' AND schema_name NOT LIKE 'pg\_toast\_temp\_%'
+		AND (cardinality($1::text[]) = 0 OR schema_name = ANY($1::text[]))
+		AND NOT schema_name = ANY($2::text[])
 		ORDER BY schema_name
-		`)
+		`, pq.Array(cfg.IncludedSchemas), pq.Array(cfg.ExcludedSchemas))
 
 	if err != nil {
 		return nil, fmt.Errorf("error querying schemas: %w", err)
@@ -45,30 +87,245 @@ func (e *PGExtractor) Extract(incluedSchemas,  excludedSchemas []string) (*model
 
 	defer rows.Close()
 
+	// the transaction's connection can only stream one result set at a time, so the
+	// names are read up front
+	var schemaNames []string
 	for rows.Next() {
 
 		var schemaName string
 		if err= rows.Scan(&schemaName); err != nil {
 			return nil, fmt.Errorf("error scanning schema: %w", err)
 		}
-		sch, err:= e.extractSchemas(schemaName)
-
-		if err != nil {
-			return nil, fmt.Errorf("error extracting schema %w", err)
-		}
-
-		dbSchema.Schemas = append(dbSchema.Schemas, sch)
+		schemaNames = append(schemaNames, schemaName)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating schemas: %w", err)
 	}
+	rows.Close()
+
+	// schemas keep the catalog's order however their parts are scheduled
+	for _, schemaName := range schemaNames {
+		dbSchema.Schemas = append(dbSchema.Schemas, newSchema(schemaName))
+	}
+
+	if err = e.extractSchemas(ctx, tx, dbSchema.Schemas); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error closing snapshot transaction: %w", err)
+	}
 
 	return dbSchema, nil
 }
 
-func (e *PGExtractor) extractSchemas(schemaName string) (*models.Schema, error) {
-	schema:= &models.Schema{
+// tableFilter restricts a query to the tables matched by e.tables. The include and
+// exclude patterns are bound to $2 and $3 so that they're never interpolated into the query
+func tableFilter(column string) string {
+	return fmt.Sprintf(`(cardinality($2::text[]) = 0 OR %[1]s ~ ANY($2::text[])) AND NOT %[1]s ~ ANY($3::text[])`, column)
+}
+
+// schemaPart is an independent piece of a schema's extraction. Each part fills its own
+// fields of the schema, so the parts of a schema can be extracted concurrently. Tables
+// aren't a part of their own, as they're split into batches by tableBatchPart
+type schemaPart struct {
+	name    string
+	extract func(e *PGExtractor, ctx context.Context, schema *models.Schema) error
+	count   func(schema *models.Schema) int
+}
+
+var schemaParts = []schemaPart{
+	{"views", (*PGExtractor).extractViews, func(s *models.Schema) int { return len(s.Views) }},
+	{"indexes", (*PGExtractor).extractIndexes, func(s *models.Schema) int { return len(s.Indexes) }},
+	{"triggers", (*PGExtractor).extractTriggers, func(s *models.Schema) int { return len(s.Triggers) }},
+	{"sequences", (*PGExtractor).extractSequences, func(s *models.Schema) int { return len(s.Sequences) }},
+	{"functions", (*PGExtractor).extractFunctions, func(s *models.Schema) int { return len(s.Functions) }},
+	{"enums", (*PGExtractor).extractEnums, func(s *models.Schema) int { return len(s.Enums) }},
+	{"domains", (*PGExtractor).extractDomains, func(s *models.Schema) int { return len(s.Domains) }},
+	{"composite types", (*PGExtractor).extractCompositeTypes, func(s *models.Schema) int { return len(s.CompositeTypes) }},
+	{"extensions", (*PGExtractor).extractExtensions, func(s *models.Schema) int { return len(s.Extensions) }},
+	{"privileges", (*PGExtractor).extractPrivileges, func(s *models.Schema) int { return len(s.Privileges) }},
+}
+
+// tableBatchSize is the most tables a single part extracts, so that the workers can share
+// the tables of a large schema
+const tableBatchSize = 250
+
+// tableBatchPart extracts the named tables of a schema into batch. The batches are added
+// to the schema once they're all extracted, so that its tables keep the catalog's order
+func tableBatchPart(names []string, batch *[]*models.Table) schemaPart {
+	// the names were matched by the table filter when they were listed, and are now
+	// matched exactly by a single pattern, which PostgreSQL compiles once
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, regexp.QuoteMeta(name))
+	}
+	pattern := "^(?:" + strings.Join(quoted, "|") + ")$"
+
+	return schemaPart{
+		name: "tables",
+		extract: func(e *PGExtractor, ctx context.Context, schema *models.Schema) error {
+			// an empty filter would match every table
+			if len(names) == 0 {
+				return nil
+			}
+
+			worker := *e
+			worker.tables = &config.TableFilter{Include: []string{pattern}}
+			extracted := newSchema(schema.Name)
+			if err := worker.extractTables(ctx, extracted); err != nil {
+				return err
+			}
+			*batch = extracted.Tables
+			return nil
+		},
+		count: func(*models.Schema) int { return len(*batch) },
+	}
+}
+
+// listTables returns the names of the tables matched by the table filter in each of the schemas
+func (e *PGExtractor) listTables(ctx context.Context, schemas []*models.Schema) (map[string][]string, error) {
+	schemaNames := make([]string, 0, len(schemas))
+	for _, schema := range schemas {
+		schemaNames = append(schemaNames, schema.Name)
+	}
+
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			n.nspname,
+			c.relname
+		FROM
+			pg_catalog.pg_class c
+		JOIN
+			pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE
+			c.relkind IN ('r', 'p')
+			AND n.nspname = ANY($1::text[])
+			AND `+tableFilter("c.relname")+`
+		ORDER BY
+			n.nspname, c.relname
+	`, pq.Array(schemaNames), pq.Array(e.tables.Include), pq.Array(e.tables.Exclude))
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := make(map[string][]string)
+	for rows.Next() {
+		var schemaName, tableName string
+		if err = rows.Scan(&schemaName, &tableName); err != nil {
+			return nil, fmt.Errorf("error scanning table: %w", err)
+		}
+		tables[schemaName] = append(tables[schemaName], tableName)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tables: %w", err)
+	}
+
+	return tables, nil
+}
+
+// schemaJob is a part of a schema's extraction, run by one of the workers
+type schemaJob struct {
+	schema *models.Schema
+	part   schemaPart
+}
+
+// progressTracker turns finished parts into progress events. Workers report to it
+// concurrently, so it serializes calls to the handler
+type progressTracker struct {
+	mu         sync.Mutex
+	handler    models.ProgressFunc
+	start      time.Time
+	tablesDone int
+	tables     int
+	started    map[string]bool
+	remaining  map[string]int
+	counts     map[string]map[string]int
+	totals     map[string]int
+}
+
+func newProgressTracker(handler models.ProgressFunc, jobs []schemaJob, tables int) *progressTracker {
+	t := &progressTracker{
+		handler:   handler,
+		start:     time.Now(),
+		tables:    tables,
+		started:   make(map[string]bool),
+		remaining: make(map[string]int),
+		counts:    make(map[string]map[string]int),
+		totals:    make(map[string]int),
+	}
+	for _, j := range jobs {
+		if t.counts[j.schema.Name] == nil {
+			t.counts[j.schema.Name] = make(map[string]int)
+		}
+		t.remaining[j.schema.Name]++
+	}
+
+	t.emit(models.ProgressEvent{Stage: models.ExtractionStarted})
+	return t
+}
+
+// emit fills in the overall progress and passes ev to the handler. Callers hold mu,
+// except during construction
+func (t *progressTracker) emit(ev models.ProgressEvent) {
+	if t.handler == nil {
+		return
+	}
+	ev.TablesDone = t.tablesDone
+	ev.Tables = t.tables
+	ev.Elapsed = time.Since(t.start)
+	t.handler(ev)
+}
+
+func (t *progressTracker) partStarted(schema *models.Schema) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// a schema starts with whichever of its parts is picked up first
+	if !t.started[schema.Name] {
+		t.started[schema.Name] = true
+		t.emit(models.ProgressEvent{Stage: models.SchemaStarted, Schema: schema.Name})
+	}
+}
+
+func (t *progressTracker) partFinished(schema *models.Schema, part schemaPart) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// a schema's tables are counted over all of its batches
+	count := part.count(schema)
+	if part.name == "tables" {
+		t.tablesDone += count
+	}
+	t.remaining[schema.Name]--
+	t.counts[schema.Name][part.name] += count
+	t.totals[part.name] += count
+
+	t.emit(models.ProgressEvent{
+		Stage:      models.ObjectsExtracted,
+		Schema:     schema.Name,
+		ObjectType: part.name,
+		Count:      count,
+	})
+
+	if t.remaining[schema.Name] == 0 {
+		t.emit(models.ProgressEvent{Stage: models.SchemaFinished, Schema: schema.Name, Counts: t.counts[schema.Name]})
+	}
+}
+
+func (t *progressTracker) finished() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.emit(models.ProgressEvent{Stage: models.ExtractionFinished, Counts: t.totals})
+}
+
+func newSchema(schemaName string) *models.Schema {
+	return &models.Schema{
 		Name: schemaName,
 		Tables: []*models.Table{},
 		Views: []*models.View{},
@@ -76,87 +333,342 @@ func (e *PGExtractor) extractSchemas(schemaName string) (*models.Schema, error)
 		Indexes: []*models.Index{},
 		Functions: []*models.Function{},
 		Sequences: []*models.Sequence{},
+		Enums: []*models.Enum{},
+		Domains: []*models.Domain{},
+		CompositeTypes: []*models.CompositeType{},
+		Extensions: []*models.Extension{},
+		Privileges: []*models.Privilege{},
+		Owners: []*models.Owner{},
+	}
+}
+
+// extractSchemas fills in every part of the given schemas. The tables are listed first, and
+// extracted in batches of tableBatchSize alongside the other parts
+func (e *PGExtractor) extractSchemas(ctx context.Context, tx *sql.Tx, schemas []*models.Schema) error {
+	tableNames, err := e.listTables(ctx, schemas)
+	if err != nil {
+		return err
 	}
 
-	var err error
+	var jobs []schemaJob
+	tables := 0
+	batches := make([][][]*models.Table, len(schemas))
+	for i, schema := range schemas {
+		names := tableNames[schema.Name]
+		tables += len(names)
 
-	if err= e.extractTables(schema); err != nil {
-		return nil, fmt.Errorf("error extracting tables %w", err)
+		// a schema without tables still gets a batch, so that it reports 0 tables
+		chunks := slices.Collect(slices.Chunk(names, tableBatchSize))
+		if len(chunks) == 0 {
+			chunks = [][]string{nil}
+		}
+
+		batches[i] = make([][]*models.Table, len(chunks))
+		for n, chunk := range chunks {
+			jobs = append(jobs, schemaJob{schema: schema, part: tableBatchPart(chunk, &batches[i][n])})
+		}
+		for _, part := range schemaParts {
+			jobs = append(jobs, schemaJob{schema: schema, part: part})
+		}
 	}
 
-	return schema, nil
+	progress := newProgressTracker(e.progress, jobs, tables)
+	if err := e.runJobs(ctx, tx, jobs, progress); err != nil {
+		return err
+	}
+
+	for i, schema := range schemas {
+		for _, batch := range batches[i] {
+			schema.Tables = append(schema.Tables, batch...)
+		}
+	}
+
+	progress.finished()
+	return nil
+}
+
+// runJobs spreads the jobs over the connection's workers, each in its own transaction
+// importing the snapshot of tx so that they all see the same state. The first error
+// cancels the remaining work.
+func (e *PGExtractor) runJobs(ctx context.Context, tx *sql.Tx, jobs []schemaJob, progress *progressTracker) error {
+	run := func(worker *PGExtractor, j schemaJob) error {
+		progress.partStarted(j.schema)
+		if err := j.part.extract(worker, ctx, j.schema); err != nil {
+			return fmt.Errorf("error extracting %s of schema %s: %w", j.part.name, j.schema.Name, err)
+		}
+		progress.partFinished(j.schema, j.part)
+		return nil
+	}
+
+	workers := min(e.conn.workers, len(jobs))
+	if workers <= 1 {
+		for _, j := range jobs {
+			if err := run(e, j); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	snapshotID, err := e.conn.ExportSnapshot(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	queue := make(chan schemaJob)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			workerTx, err := e.conn.BeginSnapshot(ctx, snapshotID)
+			if err != nil {
+				fail(err)
+				return
+			}
+			defer workerTx.Rollback()
+
+			// each worker queries through its own transaction
+			worker := *e
+			worker.q = workerTx
+
+			for j := range queue {
+				if err := run(&worker, j); err != nil {
+					fail(err)
+					return
+				}
+			}
+		}()
+	}
+
+send:
+	for _, j := range jobs {
+		select {
+		case queue <- j:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
 }
 
-func (e *PGExtractor) extractTables(schema *models.Schema) error {
-	rows, err := e.conn.Query(`
+// extractTables reads every table in the schema, then fills in their columns and constraints
+// with one query each rather than one per table
+func (e *PGExtractor) extractTables(ctx context.Context, schema *models.Schema) error {
+	rows, err := e.q.QueryContext(ctx, `
 		SELECT 
-			c.table_name, 
-			obj_description(c.oid, 'pg_class') as table_comment
+			c.relname, 
+			obj_description(c.oid, 'pg_class') as table_comment,
+			c.relrowsecurity,
+			c.relforcerowsecurity,
+			CASE WHEN c.relkind = 'p' THEN pg_get_partkeydef(c.oid) END,
+			parent.relname,
+			pg_get_expr(c.relpartbound, c.oid)
 		FROM 
 			pg_catalog.pg_class c
 		JOIN 
 			pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN
+			pg_catalog.pg_inherits inh ON inh.inhrelid = c.oid AND c.relispartition
+		LEFT JOIN
+			pg_catalog.pg_class parent ON parent.oid = inh.inhparent
 		WHERE 
-			c.relkind = 'r' 
+			c.relkind IN ('r', 'p')
 			AND n.nspname = $1
+			AND `+tableFilter("c.relname")+`
 		ORDER BY 
 			c.relname
-	`, schema.Name)
+	`, schema.Name, pq.Array(e.tables.Include), pq.Array(e.tables.Exclude))
 
 	if err != nil {
 		return fmt.Errorf("error querying tables: %w", err)
 	}
 	defer rows.Close()
 
+	tables := make(map[string]*models.Table)
+
 	for rows.Next() {
 		table:= &models.Table{
 			Schema: schema.Name,
 			Columns: []*models.Column{},
 			Constraints: []*models.Constraint{},
 		}
-		err= rows.Scan(&table.Name, &table.Comment); if err != nil {
+		var comment, partitionKey, parent, bound *string
+		err= rows.Scan(&table.Name, &comment, &table.RowSecurity, &table.ForceRowSecurity, &partitionKey, &parent, &bound); if err != nil {
 			return err
 		}
-
-		err = e.extractColumns(table)
-		if err != nil {
-			return fmt.Errorf("error extracting columns %w",  err)
+		if comment != nil {
+			table.Comment = *comment
 		}
 
-		err = e.extractConstraints(table)
-		if err != nil {
-			return fmt.Errorf("error extracting constraints %w",  err)
+		if partitionKey != nil {
+			table.PartitionStrategy, table.PartitionKey = splitPartitionKey(*partitionKey)
+		}
+		if parent != nil && bound != nil {
+			table.PartitionOf = *parent
+			table.PartitionBound = *bound
 		}
 
+		tables[table.Name] = table
 		schema.Tables = append(schema.Tables, table)
 	}
 
-	if rows.Err() != nil {
+	if err = rows.Err(); err != nil {
 		return fmt.Errorf("error iterating tables: %w", err)
 	}
+	rows.Close()
+
+	if len(tables) == 0 {
+		return nil
+	}
+
+	if err = e.extractColumns(ctx, schema, tables); err != nil {
+		return fmt.Errorf("error extracting columns %w",  err)
+	}
+
+	if err = e.extractConstraints(ctx, schema, tables); err != nil {
+		return fmt.Errorf("error extracting constraints %w",  err)
+	}
+
+	if err = e.extractPolicies(ctx, schema, tables); err != nil {
+		return fmt.Errorf("error extracting policies %w", err)
+	}
 
 	return nil
 }
 
-func (e *PGExtractor) extractColumns(table *models.Table) error {
+// extractPolicies reads the row-level security policies of every table in the schema
+func (e *PGExtractor) extractPolicies(ctx context.Context, schema *models.Schema, tables map[string]*models.Table) error {
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			p.tablename,
+			p.policyname,
+			p.permissive,
+			p.cmd,
+			p.roles::text[],
+			p.qual,
+			p.with_check
+		FROM
+			pg_catalog.pg_policies p
+		WHERE
+			p.schemaname = $1
+			AND `+tableFilter("p.tablename")+`
+		ORDER BY
+			p.tablename, p.policyname
+	`, schema.Name, pq.Array(e.tables.Include), pq.Array(e.tables.Exclude))
 
-	rows, err:= e.conn.Query(`SELECT 
-			c.column_name, 
-			c.data_type, 
-			CASE WHEN c.is_nullable = 'YES' THEN true ELSE false END, 
-			c.column_default,
-			pgd.description as column_comment
-		FROM 
-			information_schema.columns c
-		LEFT JOIN 
-			pg_catalog.pg_description pgd ON 
-				pgd.objoid = ('"' || c.table_schema || '"."' || c.table_name || '"')::regclass AND 
-				pgd.objsubid = c.ordinal_position
-		WHERE 
-			c.table_schema = $1 AND c.table_name = $2
-		ORDER BY 
-			c.ordinal_position
-	`, table.Schema, table.Name)
+	if err != nil {
+		return fmt.Errorf("error querying policies: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		policy := &models.Policy{}
+		var permissive string
+		var roles pq.StringArray
+		var using, withCheck *string
+
+		err = rows.Scan(&policy.Table, &policy.Name, &permissive, &policy.Command, &roles, &using, &withCheck)
+		if err != nil {
+			return fmt.Errorf("error scanning policy: %w", err)
+		}
+
+		policy.Restrictive = permissive == "RESTRICTIVE"
+
+		// a policy for PUBLIC applies to every role, the same as naming none
+		if len(roles) != 1 || roles[0] != "public" {
+			policy.Roles = roles
+		}
+
+		if using != nil {
+			policy.Using = *using
+		}
+		if withCheck != nil {
+			policy.WithCheck = *withCheck
+		}
+
+		table, ok := tables[policy.Table]
+		if !ok {
+			continue
+		}
+		table.Policies = append(table.Policies, policy)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating policies: %w", err)
+	}
+
+	return nil
+}
+
+// splitPartitionKey splits the output of pg_get_partkeydef, e.g. "RANGE (created_at)",
+// into the partitioning strategy and the key
+func splitPartitionKey(definition string) (string, string) {
+	strategy, key, _ := strings.Cut(definition, " ")
+	key = strings.TrimSpace(key)
+	if strings.HasPrefix(key, "(") && strings.HasSuffix(key, ")") {
+		key = key[1 : len(key)-1]
+	}
+	return strategy, key
+}
+
+// extractColumns reads the columns of every table in the schema, adding them to the matching entry of tables
+func (e *PGExtractor) extractColumns(ctx context.Context, schema *models.Schema, tables map[string]*models.Table) error {
+
+	// format_type keeps the modifiers (varchar(50), numeric(10,2)) and array brackets that
+	// information_schema.columns.data_type drops
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			c.relname,
+			a.attname,
+			format_type(a.atttypid, a.atttypmod),
+			NOT a.attnotnull,
+			a.attndims,
+			pg_get_expr(d.adbin, d.adrelid),
+			CASE a.attidentity WHEN 'a' THEN 'ALWAYS' WHEN 'd' THEN 'BY DEFAULT' END,
+			a.attgenerated::text,
+			CASE WHEN a.attcollation <> t.typcollation THEN co.collname END,
+			col_description(c.oid, a.attnum)
+		FROM
+			pg_catalog.pg_attribute a
+		JOIN
+			pg_catalog.pg_class c ON c.oid = a.attrelid
+		JOIN
+			pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		JOIN
+			pg_catalog.pg_type t ON t.oid = a.atttypid
+		LEFT JOIN
+			pg_catalog.pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		LEFT JOIN
+			pg_catalog.pg_collation co ON co.oid = a.attcollation
+		WHERE
+			n.nspname = $1
+			AND c.relkind IN ('r', 'p')
+			AND a.attnum > 0
+			AND NOT a.attisdropped
+			AND `+tableFilter("c.relname")+`
+		ORDER BY
+			c.relname, a.attnum
+	`, schema.Name, pq.Array(e.tables.Include), pq.Array(e.tables.Exclude))
 
 	if err != nil {
 		return fmt.Errorf("error querying columns %w", err)
@@ -164,39 +676,1134 @@ func (e *PGExtractor) extractColumns(table *models.Table) error {
 
 	defer rows.Close()
 
-	if rows.Next() {
+	for rows.Next() {
 		col := &models.Column{}
-		var defaultValue, comment *string 
-		rows.Scan(&col.Name, &col.DataType, &col.IsNullable, &defaultValue, &comment)
+		var tableName, dataType string
+		var dims int
+		var generated string
+		var defaultValue, identity, collation, comment *string
+		if err = rows.Scan(&tableName, &col.Name, &dataType, &col.IsNullable, &dims, &defaultValue, &identity, &generated, &collation, &comment); err != nil {
+			return fmt.Errorf("error scanning column: %w", err)
+		}
 
-	
+		table, ok := tables[tableName]
+		if !ok {
+			continue
+		}
+
+		// format_type renders every array with a single pair of brackets
+		if dims > 1 {
+			dataType += strings.Repeat("[]", dims-1)
+		}
+		col.SetDataType(dataType)
 
+		// attgenerated is s for STORED and, from PostgreSQL 18, v for VIRTUAL columns
 		if defaultValue != nil {
-			col.DefaultValue = *defaultValue
+			if generated != "" {
+				col.Generated = *defaultValue
+				col.Virtual = generated == "v"
+			} else {
+				col.DefaultValue = *defaultValue
+			}
+		}
+		if identity != nil {
+			col.Identity = *identity
+		}
+		if collation != nil {
+			col.Collation = *collation
 		}
 		if comment != nil {
-			col.Comment = 
-			*comment
+			col.Comment = *comment
 		}
 		table.Columns = append(table.Columns, col)
 	}
 
-	if err= rows.Err(); err!=nil {
+	if err = rows.Err(); err != nil {
 		return fmt.Errorf("error scanning columns %w", err)
 	}
 
 	return nil
+}
+
+// fkActions maps pg_constraint.confdeltype/confupdtype codes to their SQL keywords.
+// NO ACTION is the default and is left empty
+var fkActions = map[string]string{
+	"a": "",
+	"r": "RESTRICT",
+	"c": "CASCADE",
+	"n": "SET NULL",
+	"d": "SET DEFAULT",
+}
+
+// extractConstraints reads the constraints of every table in the schema, adding them to the matching entry of tables
+func (e *PGExtractor) extractConstraints(ctx context.Context, schema *models.Schema, tables map[string]*models.Table) error {
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			c.relname,
+			con.conname,
+			con.contype,
+			ARRAY(
+				SELECT a.attname
+				FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_catalog.pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			)::text[] AS columns,
+			COALESCE(fn.nspname, ''),
+			COALESCE(fc.relname, ''),
+			ARRAY(
+				SELECT a.attname
+				FROM unnest(con.confkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_catalog.pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			)::text[] AS ref_columns,
+			con.confdeltype,
+			con.confupdtype,
+			con.condeferrable,
+			con.condeferred,
+			pg_get_constraintdef(con.oid)
+		FROM
+			pg_catalog.pg_constraint con
+		JOIN
+			pg_catalog.pg_class c ON c.oid = con.conrelid
+		JOIN
+			pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN
+			pg_catalog.pg_class fc ON fc.oid = con.confrelid
+		LEFT JOIN
+			pg_catalog.pg_namespace fn ON fn.oid = fc.relnamespace
+		WHERE
+			n.nspname = $1
+			AND c.relkind IN ('r', 'p')
+			AND con.contype IN ('p', 'f', 'u', 'c', 'x')
+			AND `+tableFilter("c.relname")+`
+		ORDER BY
+			c.relname, con.contype, con.conname
+	`, schema.Name, pq.Array(e.tables.Include), pq.Array(e.tables.Exclude))
+
+	if err != nil {
+		return fmt.Errorf("error querying constraints: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		constraint := &models.Constraint{}
+		var tableName, conType, refSchema, refTable, delType, updType, definition string
+		var columns, refColumns []string
+
+		err = rows.Scan(
+			&tableName, &constraint.Name, &conType, pq.Array(&columns),
+			&refSchema, &refTable, pq.Array(&refColumns),
+			&delType, &updType, &constraint.Deferrable, &constraint.InitiallyDeferred,
+			&definition,
+		)
+		if err != nil {
+			return fmt.Errorf("error scanning constraint: %w", err)
+		}
+
+		table, ok := tables[tableName]
+		if !ok {
+			continue
+		}
+
+		constraint.Columns = columns
+
+		switch conType {
+		case "p":
+			constraint.Type = models.PRIMARY_KEY
+		case "u":
+			constraint.Type = models.UNIQUE
+		case "f":
+			constraint.Type = models.FOREIGN_KEY
+
+			// only qualify the referenced table when it lives in another schema, matching parsed DDL
+			ref := refTable
+			if refSchema != table.Schema {
+				ref = refSchema + "." + refTable
+			}
+			constraint.References = fmt.Sprintf("%s(%s)", ref, strings.Join(refColumns, ", "))
+			constraint.OnDelete = fkActions[delType]
+			constraint.OnUpdate = fkActions[updType]
+		case "c":
+			constraint.Type = models.CHECK
+			constraint.CheckExpr = checkExpression(definition)
+		case "x":
+			constraint.Type = models.EXCLUDE
+			constraint.Definition = definition
+		}
+
+		table.Constraints = append(table.Constraints, constraint)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating constraints: %w", err)
+	}
+
+	return nil
+}
+
+// checkExpression strips the CHECK keyword and outer parentheses from pg_get_constraintdef output,
+// e.g. "CHECK ((price > 0)) NOT VALID" becomes "(price > 0)"
+func checkExpression(definition string) string {
+	expr := strings.TrimSpace(definition)
+	expr = strings.TrimSuffix(expr, " NOT VALID")
+	expr = strings.TrimSuffix(expr, " NO INHERIT")
+	expr = strings.TrimPrefix(expr, "CHECK ")
+
+	if strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") {
+		expr = expr[1 : len(expr)-1]
+	}
+
+	return expr
+}
+
+func (e *PGExtractor) extractViews(ctx context.Context, schema *models.Schema) error {
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			c.relname,
+			c.relkind = 'm' AS materialized,
+			c.relispopulated,
+			pg_get_viewdef(c.oid, true),
+			obj_description(c.oid, 'pg_class')
+		FROM
+			pg_catalog.pg_class c
+		JOIN
+			pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE
+			c.relkind IN ('v', 'm')
+			AND n.nspname = $1
+		ORDER BY
+			c.relname
+	`, schema.Name)
+
+	if err != nil {
+		return fmt.Errorf("error querying views: %w", err)
+	}
+	defer rows.Close()
+
+	// matviews are collected so their indexes can be read once this result set is closed
+	matviews := make(map[string]*models.View)
+
+	for rows.Next() {
+		view := &models.View{
+			Schema: schema.Name,
+		}
+		var comment *string
+
+		if err = rows.Scan(&view.Name, &view.Materialized, &view.WithData, &view.Definition, &comment); err != nil {
+			return fmt.Errorf("error scanning view: %w", err)
+		}
+
+		view.Definition = strings.TrimSuffix(strings.TrimSpace(view.Definition), ";")
+		if comment != nil {
+			view.Comment = *comment
+		}
+		if view.Materialized {
+			view.Indexes = []*models.Index{}
+			matviews[view.Name] = view
+		}
+
+		schema.Views = append(schema.Views, view)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating views: %w", err)
+	}
+	rows.Close()
+
+	if len(matviews) > 0 {
+		if err = e.extractViewIndexes(ctx, schema, matviews); err != nil {
+			return fmt.Errorf("error extracting materialized view indexes: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// extractViewIndexes reads the indexes of every materialized view in the schema
+func (e *PGExtractor) extractViewIndexes(ctx context.Context, schema *models.Schema, matviews map[string]*models.View) error {
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT `+indexColumns+`
+		FROM
+			pg_catalog.pg_index ix
+		JOIN
+			pg_catalog.pg_class i ON i.oid = ix.indexrelid
+		JOIN
+			pg_catalog.pg_class t ON t.oid = ix.indrelid
+		JOIN
+			pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		JOIN
+			pg_catalog.pg_am am ON am.oid = i.relam
+		WHERE
+			n.nspname = $1
+			AND t.relkind = 'm'
+		ORDER BY
+			t.relname, i.relname
+	`, schema.Name)
+
+	if err != nil {
+		return fmt.Errorf("error querying view indexes: %w", err)
+	}
+	defer rows.Close()
+
+	indexes, err := scanIndexes(rows, schema.Name)
+	if err != nil {
+		return err
+	}
+
+	for _, index := range indexes {
+		if view, ok := matviews[index.Table]; ok {
+			view.Indexes = append(view.Indexes, index)
+		}
+	}
+
+	return nil
+}
+
+// indexColumns is the select list shared by the table and materialized view index queries.
+// Key and INCLUDE columns come from pg_get_indexdef so expression keys are rendered as SQL
+const indexColumns = `
+			i.relname,
+			t.relname,
+			am.amname,
+			ix.indisunique,
+			ix.indisvalid,
+			COALESCE(pg_get_expr(ix.indpred, ix.indrelid, true), ''),
+			pg_get_indexdef(ix.indexrelid),
+			ARRAY(
+				SELECT pg_get_indexdef(ix.indexrelid, k, true)
+				FROM generate_series(1, ix.indnkeyatts) AS k
+				ORDER BY k
+			)::text[] AS key_columns,
+			ARRAY(
+				SELECT pg_get_indexdef(ix.indexrelid, k, true)
+				FROM generate_series(ix.indnkeyatts + 1, ix.indnatts) AS k
+				ORDER BY k
+			)::text[] AS include_columns,
+			ARRAY(
+				SELECT CASE WHEN opc.opcdefault THEN '' ELSE opc.opcname END
+				FROM unnest(ix.indclass::oid[]) WITH ORDINALITY AS u(opcoid, ord)
+				JOIN pg_catalog.pg_opclass opc ON opc.oid = u.opcoid
+				ORDER BY u.ord
+			)::text[] AS opclasses,
+			ix.indoption::int2[] AS options`
+
+// scanIndexes reads rows selected with indexColumns
+func scanIndexes(rows *sql.Rows, schemaName string) ([]*models.Index, error) {
+	indexes := []*models.Index{}
+
+	for rows.Next() {
+		index := &models.Index{
+			Schema: schemaName,
+		}
+		var options []int64
+
+		err := rows.Scan(
+			&index.Name, &index.Table, &index.Method, &index.IsUnique, &index.IsValid,
+			&index.Where, &index.Definition,
+			pq.Array(&index.Columns), pq.Array(&index.Include), pq.Array(&index.OpClasses),
+			pq.Array(&options),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning index: %w", err)
+		}
+
+		// indoption also covers INCLUDE columns, which have no ordering
+		index.SortOrders = make([]string, len(index.Columns))
+		for n := range index.Columns {
+			if n < len(options) {
+				index.SortOrders[n] = sortOrder(options[n])
+			}
+		}
+
+		indexes = append(indexes, index)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating indexes: %w", err)
+	}
+
+	return indexes, nil
+}
+
+// sortOrder decodes a pg_index.indoption entry. Bit 0 is DESC and bit 1 is NULLS FIRST;
+// the defaults (ASC NULLS LAST, DESC NULLS FIRST) are left implicit
+func sortOrder(option int64) string {
+	desc := option&1 != 0
+	nullsFirst := option&2 != 0
+
+	switch {
+	case desc && nullsFirst:
+		return "DESC"
+	case desc:
+		return "DESC NULLS LAST"
+	case nullsFirst:
+		return "NULLS FIRST"
+	default:
+		return ""
+	}
+}
+
+func (e *PGExtractor) extractIndexes(ctx context.Context, schema *models.Schema) error {
+	// indexes backing the table's own constraints are already described by its constraints,
+	// and the indexes a partitioned index creates on each partition by the partitioned index.
+	// A foreign key's conindid is the index it references on the other table, which is only
+	// left out when it backs that table's own constraint
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT `+indexColumns+`
+		FROM
+			pg_catalog.pg_index ix
+		JOIN
+			pg_catalog.pg_class i ON i.oid = ix.indexrelid
+		JOIN
+			pg_catalog.pg_class t ON t.oid = ix.indrelid
+		JOIN
+			pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		JOIN
+			pg_catalog.pg_am am ON am.oid = i.relam
+		WHERE
+			n.nspname = $1
+			AND t.relkind IN ('r', 'p')
+			AND NOT i.relispartition
+			AND `+tableFilter("t.relname")+`
+			AND NOT EXISTS (
+				SELECT 1
+				FROM pg_catalog.pg_constraint con
+				WHERE con.conindid = ix.indexrelid
+					AND con.conrelid = ix.indrelid
+					AND con.contype IN ('p', 'u', 'x')
+			)
+		ORDER BY
+			t.relname, i.relname
+	`, schema.Name, pq.Array(e.tables.Include), pq.Array(e.tables.Exclude))
+
+	if err != nil {
+		return fmt.Errorf("error querying indexes: %w", err)
+	}
+	defer rows.Close()
+
+	indexes, err := scanIndexes(rows, schema.Name)
+	if err != nil {
+		return err
+	}
+	schema.Indexes = append(schema.Indexes, indexes...)
+
+	return nil
+}
+
+
+// triggerStates maps pg_trigger.tgenabled codes to a readable state
+var triggerStates = map[string]string{
+	"O": "ENABLED",
+	"D": "DISABLED",
+	"R": "REPLICA",
+	"A": "ALWAYS",
+}
+
+// pg_trigger.tgtype bits
+const (
+	triggerTypeRow      = 1 << 0
+	triggerTypeBefore   = 1 << 1
+	triggerTypeInsert   = 1 << 2
+	triggerTypeDelete   = 1 << 3
+	triggerTypeUpdate   = 1 << 4
+	triggerTypeTruncate = 1 << 5
+	triggerTypeInstead  = 1 << 6
+)
+
+func (e *PGExtractor) extractTriggers(ctx context.Context, schema *models.Schema) error {
+	// internal triggers implement foreign keys and are covered by the constraints, and the
+	// clones of a partitioned table's triggers on its partitions by the parent's trigger
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			t.tgname,
+			c.relname,
+			t.tgtype,
+			t.tgenabled,
+			ARRAY(
+				SELECT a.attname
+				FROM unnest(t.tgattr::int2[]) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_catalog.pg_attribute a ON a.attrelid = t.tgrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			)::text[] AS update_columns,
+			pn.nspname,
+			p.proname,
+			t.tgargs,
+			pg_get_triggerdef(t.oid, true)
+		FROM
+			pg_catalog.pg_trigger t
+		JOIN
+			pg_catalog.pg_class c ON c.oid = t.tgrelid
+		JOIN
+			pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		JOIN
+			pg_catalog.pg_proc p ON p.oid = t.tgfoid
+		JOIN
+			pg_catalog.pg_namespace pn ON pn.oid = p.pronamespace
+		WHERE
+			n.nspname = $1
+			AND NOT t.tgisinternal
+			AND NOT EXISTS (
+				SELECT 1
+				FROM pg_catalog.pg_depend d
+				WHERE d.classid = 'pg_catalog.pg_trigger'::regclass
+					AND d.objid = t.oid
+					AND d.deptype = 'P'
+			)
+			AND `+tableFilter("c.relname")+`
+		ORDER BY
+			c.relname, t.tgname
+	`, schema.Name, pq.Array(e.tables.Include), pq.Array(e.tables.Exclude))
+
+	if err != nil {
+		return fmt.Errorf("error querying triggers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		trigger := &models.Trigger{
+			Schema: schema.Name,
+		}
+		var tgType int64
+		var enabled, funcSchema, funcName, definition string
+		var updateColumns []string
+		var args []byte
+
+		err = rows.Scan(
+			&trigger.Name, &trigger.Table, &tgType, &enabled, pq.Array(&updateColumns),
+			&funcSchema, &funcName, &args, &definition,
+		)
+		if err != nil {
+			return fmt.Errorf("error scanning trigger: %w", err)
+		}
+
+		switch {
+		case tgType&triggerTypeInstead != 0:
+			trigger.Timing = "INSTEAD OF"
+		case tgType&triggerTypeBefore != 0:
+			trigger.Timing = "BEFORE"
+		default:
+			trigger.Timing = "AFTER"
+		}
+
+		trigger.ForEach = "STATEMENT"
+		if tgType&triggerTypeRow != 0 {
+			trigger.ForEach = "ROW"
+		}
+
+		if tgType&triggerTypeInsert != 0 {
+			trigger.Events = append(trigger.Events, "INSERT")
+		}
+		if tgType&triggerTypeUpdate != 0 {
+			if len(updateColumns) > 0 {
+				trigger.Events = append(trigger.Events, "UPDATE OF "+strings.Join(updateColumns, ", "))
+			} else {
+				trigger.Events = append(trigger.Events, "UPDATE")
+			}
+		}
+		if tgType&triggerTypeDelete != 0 {
+			trigger.Events = append(trigger.Events, "DELETE")
+		}
+		if tgType&triggerTypeTruncate != 0 {
+			trigger.Events = append(trigger.Events, "TRUNCATE")
+		}
+
+		trigger.State = triggerStates[enabled]
+		trigger.When = triggerCondition(definition)
+
+		// only qualify the function when it lives in another schema, matching parsed DDL
+		function := funcName
+		if funcSchema != schema.Name {
+			function = funcSchema + "." + funcName
+		}
+		trigger.Statement = fmt.Sprintf("%s(%s)", function, triggerArguments(args))
+
+		schema.Triggers = append(schema.Triggers, trigger)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating triggers: %w", err)
+	}
+
+	return nil
+}
+
+// triggerCondition extracts the WHEN condition from pg_get_triggerdef output, as
+// pg_trigger.tgqual can't be decompiled on its own
+func triggerCondition(definition string) string {
+	idx := strings.Index(definition, " WHEN (")
+	if idx == -1 {
+		return ""
+	}
+
+	start := idx + len(" WHEN ")
+	depth := 0
+	for i := start; i < len(definition); i++ {
+		switch definition[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return definition[start+1 : i]
+			}
+		}
+	}
+
+	return ""
+}
+
+// triggerArguments decodes pg_trigger.tgargs, a sequence of NUL terminated strings
+func triggerArguments(args []byte) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	parts := strings.Split(strings.TrimSuffix(string(args), "\x00"), "\x00")
+	quoted := make([]string, 0, len(parts))
+	for _, part := range parts {
+		quoted = append(quoted, "'"+strings.ReplaceAll(part, "'", "''")+"'")
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+func (e *PGExtractor) extractSequences(ctx context.Context, schema *models.Schema) error {
+	// identity sequences are part of their column's definition and are reported there
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			c.relname,
+			format_type(s.seqtypid, NULL),
+			s.seqstart,
+			s.seqincrement,
+			s.seqmin,
+			s.seqmax,
+			s.seqcache,
+			s.seqcycle,
+			owner.relname,
+			a.attname
+		FROM
+			pg_catalog.pg_sequence s
+		JOIN
+			pg_catalog.pg_class c ON c.oid = s.seqrelid
+		JOIN
+			pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN
+			pg_catalog.pg_depend d ON d.classid = 'pg_catalog.pg_class'::regclass
+				AND d.objid = c.oid
+				AND d.refclassid = 'pg_catalog.pg_class'::regclass
+				AND d.deptype IN ('a', 'i')
+		LEFT JOIN
+			pg_catalog.pg_class owner ON owner.oid = d.refobjid
+		LEFT JOIN
+			pg_catalog.pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+		WHERE
+			n.nspname = $1
+			AND (d.deptype IS NULL OR d.deptype = 'a')
+			AND (owner.relname IS NULL OR `+tableFilter("owner.relname")+`)
+		ORDER BY
+			c.relname
+	`, schema.Name, pq.Array(e.tables.Include), pq.Array(e.tables.Exclude))
+
+	if err != nil {
+		return fmt.Errorf("error querying sequences: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		seq := &models.Sequence{
+			Schema: schema.Name,
+		}
+		var ownerTable, ownerColumn *string
+
+		err = rows.Scan(
+			&seq.Name, &seq.DataType, &seq.Start, &seq.Increment, &seq.Min, &seq.Max,
+			&seq.Cache, &seq.Cycle, &ownerTable, &ownerColumn,
+		)
+		if err != nil {
+			return fmt.Errorf("error scanning sequence: %w", err)
+		}
+
+		if ownerTable != nil && ownerColumn != nil {
+			seq.OwnedBy = *ownerTable + "." + *ownerColumn
+		}
+
+		schema.Sequences = append(schema.Sequences, seq)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating sequences: %w", err)
+	}
+
+	return nil
+}
+
+var (
+	functionKinds = map[string]string{
+		"f": "FUNCTION",
+		"p": "PROCEDURE",
+	}
+
+	functionVolatility = map[string]string{
+		"i": "IMMUTABLE",
+		"s": "STABLE",
+		"v": "VOLATILE",
+	}
+
+	functionParallel = map[string]string{
+		"s": "SAFE",
+		"r": "RESTRICTED",
+		"u": "UNSAFE",
+	}
+)
+
+func (e *PGExtractor) extractFunctions(ctx context.Context, schema *models.Schema) error {
+	// aggregates and window functions can't be rendered by pg_get_functiondef, and
+	// functions installed by extensions belong to the extension rather than the schema
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			p.proname,
+			p.prokind,
+			pg_get_function_identity_arguments(p.oid),
+			pg_get_function_result(p.oid),
+			l.lanname,
+			p.provolatile,
+			p.prosecdef,
+			p.proisstrict,
+			p.proparallel,
+			p.prosrc,
+			pg_get_functiondef(p.oid)
+		FROM
+			pg_catalog.pg_proc p
+		JOIN
+			pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+		JOIN
+			pg_catalog.pg_language l ON l.oid = p.prolang
+		WHERE
+			n.nspname = $1
+			AND p.prokind IN ('f', 'p')
+			AND NOT EXISTS (
+				SELECT 1
+				FROM pg_catalog.pg_depend d
+				WHERE d.classid = 'pg_catalog.pg_proc'::regclass
+					AND d.objid = p.oid
+					AND d.deptype = 'e'
+			)
+		ORDER BY
+			p.proname, pg_get_function_identity_arguments(p.oid)
+	`, schema.Name)
+
+	if err != nil {
+		return fmt.Errorf("error querying functions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		function := &models.Function{
+			Schema: schema.Name,
+		}
+		var kind, volatility, parallel string
+		var returnType *string
+
+		err = rows.Scan(
+			&function.Name, &kind, &function.Arguments, &returnType, &function.Language,
+			&volatility, &function.SecurityDefiner, &function.Strict, &parallel,
+			&function.Body, &function.Definition,
+		)
+		if err != nil {
+			return fmt.Errorf("error scanning function: %w", err)
+		}
+
+		function.Kind = functionKinds[kind]
+		function.Volatility = functionVolatility[volatility]
+		function.Parallel = functionParallel[parallel]
+
+		if returnType != nil {
+			function.ReturnType = *returnType
+		}
+
+		schema.Functions = append(schema.Functions, function)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating functions: %w", err)
+	}
+
+	return nil
+}
+
+// userTypeFilter restricts a pg_type query to the types of a schema, leaving out those
+// installed by extensions
+const userTypeFilter = `
+			n.nspname = $1
+			AND NOT EXISTS (
+				SELECT 1
+				FROM pg_catalog.pg_depend d
+				WHERE d.classid = 'pg_catalog.pg_type'::regclass
+					AND d.objid = t.oid
+					AND d.deptype = 'e'
+			)`
+
+func (e *PGExtractor) extractEnums(ctx context.Context, schema *models.Schema) error {
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			t.typname,
+			ARRAY(
+				SELECT enumlabel
+				FROM pg_catalog.pg_enum
+				WHERE enumtypid = t.oid
+				ORDER BY enumsortorder
+			)
+		FROM
+			pg_catalog.pg_type t
+		JOIN
+			pg_catalog.pg_namespace n ON n.oid = t.typnamespace
+		WHERE
+			t.typtype = 'e'
+			AND `+userTypeFilter+`
+		ORDER BY
+			t.typname
+	`, schema.Name)
+
+	if err != nil {
+		return fmt.Errorf("error querying enums: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		enum := &models.Enum{
+			Schema: schema.Name,
+		}
+		var labels pq.StringArray
+
+		if err := rows.Scan(&enum.Name, &labels); err != nil {
+			return fmt.Errorf("error scanning enum: %w", err)
+		}
+		enum.Labels = labels
+
+		schema.Enums = append(schema.Enums, enum)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating enums: %w", err)
+	}
 
+	return nil
 }
 
-func (e *PGExtractor) extractConstraints(schema *models.Table) error
+func (e *PGExtractor) extractDomains(ctx context.Context, schema *models.Schema) error {
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			t.typname,
+			format_type(t.typbasetype, t.typtypmod),
+			t.typnotnull,
+			t.typdefault,
+			ARRAY(
+				SELECT conname
+				FROM pg_catalog.pg_constraint
+				WHERE contypid = t.oid AND contype = 'c'
+				ORDER BY conname
+			),
+			ARRAY(
+				SELECT pg_get_constraintdef(oid)
+				FROM pg_catalog.pg_constraint
+				WHERE contypid = t.oid AND contype = 'c'
+				ORDER BY conname
+			)
+		FROM
+			pg_catalog.pg_type t
+		JOIN
+			pg_catalog.pg_namespace n ON n.oid = t.typnamespace
+		WHERE
+			t.typtype = 'd'
+			AND `+userTypeFilter+`
+		ORDER BY
+			t.typname
+	`, schema.Name)
+
+	if err != nil {
+		return fmt.Errorf("error querying domains: %w", err)
+	}
+	defer rows.Close()
 
-func (e *PGExtractor) extractViews(schema *models.Schema) error
+	for rows.Next() {
+		domain := &models.Domain{
+			Schema: schema.Name,
+		}
+		var defaultValue *string
+		var names, definitions pq.StringArray
 
-func (e *PGExtractor) extractIndexes(schema *models.Schema) error
+		err = rows.Scan(&domain.Name, &domain.BaseType, &domain.NotNull, &defaultValue, &names, &definitions)
+		if err != nil {
+			return fmt.Errorf("error scanning domain: %w", err)
+		}
 
-func (e *PGExtractor) extractTriggers(schema *models.Schema) error
+		if defaultValue != nil {
+			domain.DefaultValue = *defaultValue
+		}
 
-func (e *PGExtractor) extractSequences(schema *models.Schema) error
+		for i, name := range names {
+			domain.Constraints = append(domain.Constraints, &models.Constraint{
+				Name:      name,
+				Type:      "CHECK",
+				CheckExpr: checkExpression(definitions[i]),
+			})
+		}
 
+		schema.Domains = append(schema.Domains, domain)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating domains: %w", err)
+	}
+
+	return nil
+}
+
+func (e *PGExtractor) extractCompositeTypes(ctx context.Context, schema *models.Schema) error {
+	// every table and view has a composite row type as well, only the stand-alone
+	// ones (relkind 'c') are created with CREATE TYPE
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			t.typname,
+			ARRAY(
+				SELECT a.attname
+				FROM pg_catalog.pg_attribute a
+				WHERE a.attrelid = c.oid AND a.attnum > 0 AND NOT a.attisdropped
+				ORDER BY a.attnum
+			),
+			ARRAY(
+				SELECT format_type(a.atttypid, a.atttypmod)
+				FROM pg_catalog.pg_attribute a
+				WHERE a.attrelid = c.oid AND a.attnum > 0 AND NOT a.attisdropped
+				ORDER BY a.attnum
+			)
+		FROM
+			pg_catalog.pg_type t
+		JOIN
+			pg_catalog.pg_namespace n ON n.oid = t.typnamespace
+		JOIN
+			pg_catalog.pg_class c ON c.oid = t.typrelid
+		WHERE
+			t.typtype = 'c'
+			AND c.relkind = 'c'
+			AND `+userTypeFilter+`
+		ORDER BY
+			t.typname
+	`, schema.Name)
+
+	if err != nil {
+		return fmt.Errorf("error querying composite types: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		composite := &models.CompositeType{
+			Schema: schema.Name,
+		}
+		var names, types pq.StringArray
 
+		if err := rows.Scan(&composite.Name, &names, &types); err != nil {
+			return fmt.Errorf("error scanning composite type: %w", err)
+		}
+
+		for i, name := range names {
+			composite.Attributes = append(composite.Attributes, &models.Column{
+				Name:     name,
+				DataType: types[i],
+			})
+		}
+
+		schema.CompositeTypes = append(schema.CompositeTypes, composite)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating composite types: %w", err)
+	}
+
+	return nil
+}
+
+func (e *PGExtractor) extractExtensions(ctx context.Context, schema *models.Schema) error {
+	// an extension belongs to the schema its objects were installed into
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT
+			e.extname,
+			e.extversion
+		FROM
+			pg_catalog.pg_extension e
+		JOIN
+			pg_catalog.pg_namespace n ON n.oid = e.extnamespace
+		WHERE
+			n.nspname = $1
+		ORDER BY
+			e.extname
+	`, schema.Name)
+
+	if err != nil {
+		return fmt.Errorf("error querying extensions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		ext := &models.Extension{
+			Schema: schema.Name,
+		}
+
+		if err := rows.Scan(&ext.Name, &ext.Version); err != nil {
+			return fmt.Errorf("error scanning extension: %w", err)
+		}
+
+		schema.Extensions = append(schema.Extensions, ext)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating extensions: %w", err)
+	}
+
+	return nil
+}
+
+// aclObjects lists the schema, its tables, views, sequences and routines along with their
+// owner, ACL and the kind of default ACL acldefault() builds for them
+func aclObjects() string {
+	return `
+		WITH objects AS (
+			SELECT
+				CASE c.relkind WHEN 'S' THEN 'SEQUENCE' ELSE 'TABLE' END AS object_type,
+				c.relname::text AS object,
+				c.relowner AS owner,
+				c.relacl AS acl,
+				CASE c.relkind WHEN 'S' THEN 's' ELSE 'r' END::"char" AS acl_kind
+			FROM
+				pg_catalog.pg_class c
+			JOIN
+				pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+			WHERE
+				n.nspname = $1
+				AND c.relkind IN ('r', 'p', 'v', 'm', 'f', 'S')
+				AND (c.relkind NOT IN ('r', 'p') OR ` + tableFilter("c.relname") + `)
+			UNION ALL
+			SELECT
+				CASE p.prokind WHEN 'p' THEN 'PROCEDURE' ELSE 'FUNCTION' END,
+				p.proname || '(' || oidvectortypes(p.proargtypes) || ')',
+				p.proowner,
+				p.proacl,
+				'f'::"char"
+			FROM
+				pg_catalog.pg_proc p
+			JOIN
+				pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+			WHERE
+				n.nspname = $1
+				AND p.prokind IN ('f', 'p')
+				AND NOT EXISTS (
+					SELECT 1
+					FROM pg_catalog.pg_depend d
+					WHERE d.classid = 'pg_catalog.pg_proc'::regclass
+						AND d.objid = p.oid
+						AND d.deptype = 'e'
+				)
+			UNION ALL
+			SELECT
+				'SCHEMA',
+				n.nspname::text,
+				n.nspowner,
+				n.nspacl,
+				'n'::"char"
+			FROM
+				pg_catalog.pg_namespace n
+			WHERE
+				n.nspname = $1
+		)`
+}
+
+// extractPrivileges reads the owner of every object in the schema and the privileges granted
+// on them. Only privileges that differ from the defaults are reported: the owner's own privileges
+// and PUBLIC's EXECUTE on routines are implied by CREATE, so schema files only list grants on
+// top of them, and the defaults that were revoked
+func (e *PGExtractor) extractPrivileges(ctx context.Context, schema *models.Schema) error {
+	rows, err := e.q.QueryContext(ctx, aclObjects()+`
+		SELECT
+			o.object_type,
+			o.object,
+			CASE a.grantee WHEN 0 THEN 'public' ELSE pg_get_userbyid(a.grantee) END,
+			a.privilege_type,
+			a.is_grantable,
+			a.revoked
+		FROM
+			objects o,
+			LATERAL (
+				(
+					SELECT grantee, privilege_type, is_grantable, false AS revoked
+					FROM aclexplode(COALESCE(o.acl, acldefault(o.acl_kind, o.owner)))
+					EXCEPT
+					SELECT grantee, privilege_type, is_grantable, false
+					FROM aclexplode(acldefault(o.acl_kind, o.owner))
+				)
+				UNION ALL
+				(
+					-- a default is only revoked when the grantee lost the privilege, not its grant option
+					SELECT grantee, privilege_type, false, true
+					FROM aclexplode(acldefault(o.acl_kind, o.owner))
+					EXCEPT
+					SELECT grantee, privilege_type, false, true
+					FROM aclexplode(COALESCE(o.acl, acldefault(o.acl_kind, o.owner)))
+				)
+			) a
+		ORDER BY
+			1, 2, 3, 4
+	`, schema.Name, pq.Array(e.tables.Include), pq.Array(e.tables.Exclude))
+
+	if err != nil {
+		return fmt.Errorf("error querying privileges: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		privilege := &models.Privilege{}
+
+		err = rows.Scan(&privilege.ObjectType, &privilege.Object, &privilege.Grantee, &privilege.Privilege, &privilege.Grantable, &privilege.Revoked)
+		if err != nil {
+			return fmt.Errorf("error scanning privilege: %w", err)
+		}
+
+		schema.Privileges = append(schema.Privileges, privilege)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating privileges: %w", err)
+	}
+	rows.Close()
+
+	rows, err = e.q.QueryContext(ctx, aclObjects()+`
+		SELECT
+			o.object_type,
+			o.object,
+			pg_get_userbyid(o.owner)
+		FROM
+			objects o
+		ORDER BY
+			1, 2
+	`, schema.Name, pq.Array(e.tables.Include), pq.Array(e.tables.Exclude))
+
+	if err != nil {
+		return fmt.Errorf("error querying owners: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		owner := &models.Owner{}
+
+		if err := rows.Scan(&owner.ObjectType, &owner.Object, &owner.Owner); err != nil {
+			return fmt.Errorf("error scanning owner: %w", err)
+		}
+
+		schema.Owners = append(schema.Owners, owner)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating owners: %w", err)
+	}
+
+	return nil
+}
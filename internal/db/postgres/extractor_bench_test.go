@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Richd0tcom/schedrift/internal/config"
+	"github.com/Richd0tcom/schedrift/internal/models"
+)
+
+// benchmarkRoundTrip is how long each fake query takes, standing in for a database over a WAN link
+const benchmarkRoundTrip = 100 * time.Microsecond
+
+// syntheticCatalog answers the queries of extractTables for the given number of tables, each
+// with 8 columns, a primary key, a foreign key to the table before it and a CHECK constraint
+func syntheticCatalog(tableCount int) []fakeQuery {
+	tables := fakeQuery{
+		match:   "c.relrowsecurity",
+		columns: []string{"relname", "table_comment", "relrowsecurity", "relforcerowsecurity", "pg_get_partkeydef", "relname", "pg_get_expr"},
+	}
+	columns := fakeQuery{
+		match:   "format_type(a.atttypid, a.atttypmod)",
+		columns: []string{"relname", "attname", "format_type", "nullable", "attndims", "default", "identity", "attgenerated", "collname", "col_description"},
+	}
+	constraints := fakeQuery{
+		match:   "pg_get_constraintdef(con.oid)",
+		columns: []string{"relname", "conname", "contype", "columns", "nspname", "relname", "ref_columns", "confdeltype", "confupdtype", "condeferrable", "condeferred", "pg_get_constraintdef"},
+	}
+	policies := fakeQuery{
+		match:   "pg_catalog.pg_policies",
+		columns: []string{"tablename", "policyname", "permissive", "cmd", "roles", "qual", "with_check"},
+	}
+
+	for n := range tableCount {
+		name := fmt.Sprintf("table_%04d", n)
+		tables.rows = append(tables.rows, []driver.Value{name, "synthetic", false, false, nil, nil, nil})
+
+		columns.rows = append(columns.rows,
+			[]driver.Value{name, "id", "bigint", false, int64(0), nil, "ALWAYS", "", nil, nil},
+			[]driver.Value{name, "parent_id", "bigint", true, int64(0), nil, nil, "", nil, nil},
+		)
+		for c := range 5 {
+			columns.rows = append(columns.rows, []driver.Value{name, fmt.Sprintf("value_%d", c), "character varying(100)", true, int64(0), "''::character varying", nil, "", nil, nil})
+		}
+		columns.rows = append(columns.rows, []driver.Value{name, "total", "numeric(10,2)", true, int64(0), "(value_0)::numeric", nil, "s", nil, nil})
+
+		constraints.rows = append(constraints.rows,
+			[]driver.Value{name, name + "_total_check", "c", "{total}", "", "", "{}", " ", " ", false, false, "CHECK ((total >= (0)::numeric))"},
+			[]driver.Value{name, name + "_pkey", "p", "{id}", "", "", "{}", " ", " ", false, false, "PRIMARY KEY (id)"},
+		)
+		if n > 0 {
+			parent := fmt.Sprintf("table_%04d", n-1)
+			constraints.rows = append(constraints.rows, []driver.Value{
+				name, name + "_parent_id_fkey", "f", "{parent_id}", "app", parent, "{id}", "c", "a", false, false,
+				fmt.Sprintf("FOREIGN KEY (parent_id) REFERENCES %s(id) ON DELETE CASCADE", parent),
+			})
+		}
+	}
+
+	return []fakeQuery{tables, columns, constraints, policies}
+}
+
+// BenchmarkExtractTables compares reading a large schema's tables with the same queries
+// restricted to one table at a time, as the per-table extraction did, against reading the
+// whole schema at once
+func BenchmarkExtractTables(b *testing.B) {
+	const tableCount = 1000
+	catalog := syntheticCatalog(tableCount)
+	ctx := context.Background()
+
+	run := func(b *testing.B, extract func(e *PGExtractor) (*models.Schema, error)) {
+		db, connector := openFake(benchmarkRoundTrip, catalog...)
+		e := NewPGExtractor(NewConnectionFromDB(db, config.DatabaseConfig{}))
+		defer e.conn.Close()
+
+		b.ResetTimer()
+		connector.Count.Store(0)
+		for range b.N {
+			schema, err := extract(e)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if len(schema.Tables) != tableCount {
+				b.Fatalf("extracted %d tables, want %d", len(schema.Tables), tableCount)
+			}
+		}
+		b.ReportMetric(float64(connector.Count.Load())/float64(b.N), "queries/op")
+	}
+
+	b.Run("per-table", func(b *testing.B) {
+		filters := make([]*config.TableFilter, 0, tableCount)
+		for _, row := range catalog[0].rows {
+			filter, err := config.SchemaConfig{IncludedTables: []string{row[0].(string)}}.TableFilter()
+			if err != nil {
+				b.Fatal(err)
+			}
+			filters = append(filters, filter)
+		}
+
+		run(b, func(e *PGExtractor) (*models.Schema, error) {
+			schema := newSchema("app")
+			for _, filter := range filters {
+				e.tables = filter
+				table := newSchema("app")
+				if err := e.extractTables(ctx, table); err != nil {
+					return nil, err
+				}
+				schema.Tables = append(schema.Tables, table.Tables...)
+			}
+			return schema, nil
+		})
+	})
+
+	b.Run("per-schema", func(b *testing.B) {
+		run(b, func(e *PGExtractor) (*models.Schema, error) {
+			e.tables = &config.TableFilter{}
+			schema := newSchema("app")
+			return schema, e.extractTables(ctx, schema)
+		})
+	})
+}
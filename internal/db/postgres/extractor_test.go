@@ -0,0 +1,359 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Richd0tcom/schedrift/internal/config"
+	"github.com/Richd0tcom/schedrift/internal/models"
+)
+
+// tableQueries answers the queries of extractTables for a schema app holding users, posts and a
+// partitioned events table, as PostgreSQL would. Columns of ghost belong to a table that isn't listed
+func tableQueries() []fakeQuery {
+	return []fakeQuery{
+		{
+			match:   "c.relrowsecurity",
+			columns: []string{"relname", "table_comment", "relrowsecurity", "relforcerowsecurity", "pg_get_partkeydef", "relname", "pg_get_expr"},
+			rows: [][]driver.Value{
+				{"events", nil, false, false, "RANGE (created_at)", nil, nil},
+				{"events_2024", nil, false, false, nil, "events", "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')"},
+				{"posts", nil, true, false, nil, nil, nil},
+				{"users", "people", false, false, nil, nil, nil},
+			},
+		},
+		{
+			match:   "format_type(a.atttypid, a.atttypmod)",
+			columns: []string{"relname", "attname", "format_type", "nullable", "attndims", "default", "identity", "attgenerated", "collname", "col_description"},
+			rows: [][]driver.Value{
+				{"events", "created_at", "timestamp with time zone", false, int64(0), nil, nil, "", nil, nil},
+				{"events_2024", "created_at", "timestamp with time zone", false, int64(0), nil, nil, "", nil, nil},
+				{"ghost", "id", "integer", false, int64(0), nil, nil, "", nil, nil},
+				{"posts", "id", "bigint", false, int64(0), nil, "BY DEFAULT", "", nil, nil},
+				{"posts", "user_id", "integer", true, int64(0), nil, nil, "", nil, nil},
+				{"posts", "title", "character varying(200)", false, int64(0), "'untitled'::character varying", nil, "", nil, nil},
+				{"posts", "slug", "text", true, int64(0), "lower((title)::text)", nil, "s", "C", nil},
+				{"posts", "tags", "text[]", true, int64(2), nil, nil, "", nil, "tag grid"},
+				{"users", "id", "integer", false, int64(0), "nextval('users_id_seq'::regclass)", nil, "", nil, nil},
+				{"users", "email", "text", false, int64(0), nil, nil, "", nil, nil},
+				{"users", "initials", "text", true, int64(0), "upper(left(email, 2))", nil, "v", nil, nil},
+			},
+		},
+		{
+			match:   "pg_get_constraintdef(con.oid)",
+			columns: []string{"relname", "conname", "contype", "columns", "nspname", "relname", "ref_columns", "confdeltype", "confupdtype", "condeferrable", "condeferred", "pg_get_constraintdef"},
+			rows: [][]driver.Value{
+				{"ghost", "ghost_pkey", "p", "{id}", "", "", "{}", " ", " ", false, false, "PRIMARY KEY (id)"},
+				{"posts", "posts_price_check", "c", "{}", "", "", "{}", " ", " ", false, false, "CHECK ((length((title)::text) > 0)) NOT VALID"},
+				{"posts", "posts_auditor_fkey", "f", "{user_id}", "audit", "users", "{id}", "a", "a", true, true, "FOREIGN KEY (user_id) REFERENCES audit.users(id) DEFERRABLE INITIALLY DEFERRED"},
+				{"posts", "posts_user_id_fkey", "f", "{user_id}", "app", "users", "{id}", "c", "a", false, false, "FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE"},
+				{"posts", "posts_pkey", "p", "{id}", "", "", "{}", " ", " ", false, false, "PRIMARY KEY (id)"},
+				{"users", "users_pkey", "p", "{id}", "", "", "{}", " ", " ", false, false, "PRIMARY KEY (id)"},
+				{"users", "users_email_key", "u", "{email}", "", "", "{}", " ", " ", false, false, "UNIQUE (email)"},
+			},
+		},
+		{
+			match:   "pg_catalog.pg_policies",
+			columns: []string{"tablename", "policyname", "permissive", "cmd", "roles", "qual", "with_check"},
+			rows: [][]driver.Value{
+				{"posts", "posts_owner", "PERMISSIVE", "ALL", "{public}", "(user_id = 1)", nil},
+			},
+		},
+	}
+}
+
+// extractFakeTables runs extractTables for schema app against tableQueries
+func extractFakeTables(t *testing.T, cfg config.SchemaConfig) *models.Schema {
+	t.Helper()
+
+	db, _ := openFake(0, tableQueries()...)
+	e := NewPGExtractor(NewConnectionFromDB(db, config.DatabaseConfig{}))
+	defer e.conn.Close()
+
+	tables, err := cfg.TableFilter()
+	if err != nil {
+		t.Fatalf("invalid table filter: %v", err)
+	}
+	e.tables = tables
+
+	schema := newSchema("app")
+	if err := e.extractTables(context.Background(), schema); err != nil {
+		t.Fatalf("extraction failed: %v", err)
+	}
+	return schema
+}
+
+func findTable(t *testing.T, schema *models.Schema, name string) *models.Table {
+	t.Helper()
+	for _, table := range schema.Tables {
+		if table.Name == name {
+			return table
+		}
+	}
+	t.Fatalf("table %s wasn't extracted", name)
+	return nil
+}
+
+func TestExtractTablesAssemblesColumns(t *testing.T) {
+	schema := extractFakeTables(t, config.SchemaConfig{})
+
+	var names []string
+	for _, table := range schema.Tables {
+		names = append(names, table.Name)
+	}
+	if want := []string{"events", "events_2024", "posts", "users"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("got tables %v, want %v", names, want)
+	}
+
+	events := findTable(t, schema, "events")
+	if events.PartitionStrategy != "RANGE" || events.PartitionKey != "created_at" {
+		t.Errorf("unexpected partitioning of events: %s %s", events.PartitionStrategy, events.PartitionKey)
+	}
+	if partition := findTable(t, schema, "events_2024"); partition.PartitionOf != "events" || partition.PartitionBound == "" {
+		t.Errorf("expected events_2024 to be a partition of events, got %+v", partition)
+	}
+
+	// columns keep their order within each table
+	posts := findTable(t, schema, "posts")
+	if !posts.RowSecurity || len(posts.Columns) != 5 || posts.Columns[0].Name != "id" || posts.Columns[4].Name != "tags" {
+		t.Fatalf("unexpected posts: %+v", posts)
+	}
+	if id := posts.Columns[0]; id.Identity != "BY DEFAULT" || id.IsNullable {
+		t.Errorf("unexpected posts.id: %+v", id)
+	}
+	if title := posts.Columns[2]; title.DefaultValue != "'untitled'::character varying" || title.Generated != "" {
+		t.Errorf("unexpected posts.title: %+v", title)
+	}
+	if slug := posts.Columns[3]; slug.Generated != "lower((title)::text)" || slug.DefaultValue != "" || slug.Virtual || slug.Collation != "C" {
+		t.Errorf("expected posts.slug to be a stored generated column, got %+v", slug)
+	}
+	// format_type renders a single pair of brackets whatever the dimensions
+	if tags := posts.Columns[4]; tags.DataType != "text[][]" || tags.Comment != "tag grid" {
+		t.Errorf("unexpected posts.tags: %+v", tags)
+	}
+
+	users := findTable(t, schema, "users")
+	if users.Comment != "people" || len(users.Columns) != 3 {
+		t.Fatalf("unexpected users: %+v", users)
+	}
+	if initials := users.Columns[2]; initials.Generated == "" || !initials.Virtual {
+		t.Errorf("expected users.initials to be a virtual generated column, got %+v", initials)
+	}
+}
+
+func TestExtractTablesAssemblesConstraints(t *testing.T) {
+	schema := extractFakeTables(t, config.SchemaConfig{})
+
+	tests := map[string][]*models.Constraint{
+		"events":      {},
+		"events_2024": {},
+		"posts": {
+			{Name: "posts_price_check", Type: models.CHECK, Columns: []string{}, CheckExpr: "(length((title)::text) > 0)"},
+			{Name: "posts_auditor_fkey", Type: models.FOREIGN_KEY, Columns: []string{"user_id"}, References: "audit.users(id)", Deferrable: true, InitiallyDeferred: true},
+			{Name: "posts_user_id_fkey", Type: models.FOREIGN_KEY, Columns: []string{"user_id"}, References: "users(id)", OnDelete: "CASCADE"},
+			{Name: "posts_pkey", Type: models.PRIMARY_KEY, Columns: []string{"id"}},
+		},
+		"users": {
+			{Name: "users_pkey", Type: models.PRIMARY_KEY, Columns: []string{"id"}},
+			{Name: "users_email_key", Type: models.UNIQUE, Columns: []string{"email"}},
+		},
+	}
+
+	for tableName, want := range tests {
+		table := findTable(t, schema, tableName)
+		if !reflect.DeepEqual(table.Constraints, want) {
+			t.Errorf("constraints of %s:", tableName)
+			for _, constraint := range table.Constraints {
+				t.Errorf("  got %+v", *constraint)
+			}
+		}
+	}
+
+	// a policy for PUBLIC names no roles
+	posts := findTable(t, schema, "posts")
+	want := []*models.Policy{{Name: "posts_owner", Table: "posts", Command: "ALL", Using: "(user_id = 1)"}}
+	if !reflect.DeepEqual(posts.Policies, want) {
+		t.Errorf("unexpected policies of posts: %+v", posts.Policies)
+	}
+}
+
+func TestExtractTablesFilter(t *testing.T) {
+	schema := extractFakeTables(t, config.SchemaConfig{IncludedTables: []string{"events*"}, ExcludedTables: []string{"events_2024"}})
+
+	if len(schema.Tables) != 1 || schema.Tables[0].Name != "events" || len(schema.Tables[0].Columns) != 1 {
+		t.Fatalf("expected only events to be extracted, got %+v", schema.Tables)
+	}
+}
+
+// extractBatched runs extractSchemas for schema app holding the given number of synthetic
+// tables, with the schema's other parts left out, and returns its tables and progress events
+func extractBatched(t *testing.T, tableCount, workers int) (*models.Schema, []models.ProgressEvent) {
+	t.Helper()
+
+	parts := schemaParts
+	schemaParts = nil
+	t.Cleanup(func() { schemaParts = parts })
+
+	catalog := syntheticCatalog(tableCount)
+	list := fakeQuery{match: "n.nspname = ANY($1::text[])", columns: []string{"nspname", "relname"}, tableColumn: 1}
+	for _, row := range catalog[0].rows {
+		list.rows = append(list.rows, []driver.Value{"app", row[0]})
+	}
+	snapshot := fakeQuery{match: "pg_export_snapshot()", columns: []string{"pg_export_snapshot"}, rows: [][]driver.Value{{"00000003-0000001B-1"}}}
+
+	db, _ := openFake(0, append(catalog, list, snapshot)...)
+	e := NewPGExtractor(NewConnectionFromDB(db, config.DatabaseConfig{Workers: workers}))
+	defer e.conn.Close()
+
+	var events []models.ProgressEvent
+	e.OnProgress(func(ev models.ProgressEvent) { events = append(events, ev) })
+
+	tx, err := e.conn.BeginSnapshot(context.Background(), "")
+	if err != nil {
+		t.Fatalf("error starting snapshot: %v", err)
+	}
+	defer tx.Rollback()
+
+	schema := newSchema("app")
+	if err := e.extractSchemas(context.Background(), tx, []*models.Schema{schema}); err != nil {
+		t.Fatalf("extraction failed: %v", err)
+	}
+	return schema, events
+}
+
+func TestExtractSchemasBatchesTables(t *testing.T) {
+	const tableCount = 2*tableBatchSize + 100
+
+	for _, workers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			schema, events := extractBatched(t, tableCount, workers)
+
+			// the batches' tables keep the catalog's order
+			if len(schema.Tables) != tableCount {
+				t.Fatalf("extracted %d tables, want %d", len(schema.Tables), tableCount)
+			}
+			for n, table := range schema.Tables {
+				if want := fmt.Sprintf("table_%04d", n); table.Name != want || len(table.Columns) != 8 {
+					t.Fatalf("table %d is %s with %d columns, want %s with 8", n, table.Name, len(table.Columns), want)
+				}
+			}
+
+			var batchCounts []int
+			for _, ev := range events {
+				if ev.Tables != tableCount {
+					t.Errorf("%s event counts %d tables, want %d", ev.Stage, ev.Tables, tableCount)
+				}
+				if ev.Stage == models.ObjectsExtracted && ev.ObjectType == "tables" {
+					batchCounts = append(batchCounts, ev.Count)
+				}
+			}
+			if len(batchCounts) != 3 {
+				t.Errorf("expected the tables to be extracted in 3 batches, got %v", batchCounts)
+			}
+
+			last := events[len(events)-1]
+			if last.Stage != models.ExtractionFinished || last.TablesDone != tableCount || last.Counts["tables"] != tableCount {
+				t.Errorf("unexpected last event: %+v", last)
+			}
+		})
+	}
+}
+
+func TestExtractSchemasWithoutTables(t *testing.T) {
+	schema, events := extractBatched(t, 0, 1)
+
+	if len(schema.Tables) != 0 {
+		t.Fatalf("expected no tables, got %d", len(schema.Tables))
+	}
+	// a schema without tables reports 0 rather than leaving them out
+	for _, ev := range events {
+		if ev.Stage == models.SchemaFinished {
+			if count, ok := ev.Counts["tables"]; !ok || count != 0 {
+				t.Errorf("expected 0 tables to be reported, got %v", ev.Counts)
+			}
+		}
+	}
+}
+
+func TestExtractIndexesKeepsIndexesReferencedByForeignKeys(t *testing.T) {
+	// users_email_idx is a standalone unique index that a foreign key of posts references. The
+	// fake only answers when the query leaves out the indexes of the table's own constraints,
+	// rather than every index a constraint points at
+	indexes := fakeQuery{
+		match:       "AND con.conrelid = ix.indrelid\n\t\t\t\t\tAND con.contype IN ('p', 'u', 'x')",
+		columns:     []string{"relname", "relname", "amname", "indisunique", "indisvalid", "indpred", "indexdef", "key_columns", "include_columns", "opclasses", "options"},
+		tableColumn: 1,
+		rows: [][]driver.Value{
+			{"users_email_idx", "users", "btree", true, true, "", "CREATE UNIQUE INDEX users_email_idx ON app.users USING btree (email)", "{email}", "{}", "{\"\"}", "{0}"},
+		},
+	}
+
+	db, _ := openFake(0, indexes)
+	e := NewPGExtractor(NewConnectionFromDB(db, config.DatabaseConfig{}))
+	defer e.conn.Close()
+
+	schema := newSchema("app")
+	if err := e.extractIndexes(context.Background(), schema); err != nil {
+		t.Fatalf("extraction failed: %v", err)
+	}
+
+	want := []*models.Index{{
+		Name:       "users_email_idx",
+		Schema:     "app",
+		Table:      "users",
+		Columns:    []string{"email"},
+		Include:    []string{},
+		IsUnique:   true,
+		IsValid:    true,
+		Method:     "btree",
+		OpClasses:  []string{""},
+		SortOrders: []string{""},
+		Definition: "CREATE UNIQUE INDEX users_email_idx ON app.users USING btree (email)",
+	}}
+	if !reflect.DeepEqual(schema.Indexes, want) {
+		t.Errorf("unexpected indexes: %+v", schema.Indexes)
+	}
+}
+
+func TestExtractPrivilegesRevokedDefaults(t *testing.T) {
+	privileges := fakeQuery{
+		match:       "AS revoked",
+		columns:     []string{"object_type", "object", "grantee", "privilege_type", "is_grantable", "revoked"},
+		tableColumn: 1,
+		rows: [][]driver.Value{
+			{"FUNCTION", "add(integer, integer)", "public", "EXECUTE", false, true},
+			{"TABLE", "posts", "reporting", "SELECT", true, false},
+		},
+	}
+	owners := fakeQuery{
+		match:       "pg_get_userbyid(o.owner)",
+		columns:     []string{"object_type", "object", "owner"},
+		tableColumn: 1,
+		rows:        [][]driver.Value{{"TABLE", "posts", "app_owner"}},
+	}
+
+	db, _ := openFake(0, privileges, owners)
+	e := NewPGExtractor(NewConnectionFromDB(db, config.DatabaseConfig{}))
+	defer e.conn.Close()
+
+	schema := newSchema("app")
+	if err := e.extractPrivileges(context.Background(), schema); err != nil {
+		t.Fatalf("extraction failed: %v", err)
+	}
+
+	want := []*models.Privilege{
+		{ObjectType: "FUNCTION", Object: "add(integer, integer)", Grantee: "public", Privilege: "EXECUTE", Revoked: true},
+		{ObjectType: "TABLE", Object: "posts", Grantee: "reporting", Privilege: "SELECT", Grantable: true},
+	}
+	if !reflect.DeepEqual(schema.Privileges, want) {
+		for _, priv := range schema.Privileges {
+			t.Errorf("got %+v", *priv)
+		}
+	}
+	if got := schema.Privileges[0].ToSQL("app"); got != "REVOKE EXECUTE ON FUNCTION app.add(integer, integer) FROM PUBLIC;\n" {
+		t.Errorf("unexpected dump of the revoked privilege: %q", got)
+	}
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Richd0tcom/schedrift/internal/db/dbtest"
+	"github.com/lib/pq"
+)
+
+// fakeQuery is a canned answer to any query containing match. Each row holds the name of
+// the table it belongs to in tableColumn, so that the table patterns bound by tableFilter can
+// be applied
+type fakeQuery struct {
+	match       string
+	columns     []string
+	rows        [][]driver.Value
+	tableColumn int
+
+	byTable map[string][][]driver.Value
+}
+
+// openFake returns a database backed by the given queries, each waiting for roundTrip, and
+// the connector counting them
+func openFake(roundTrip time.Duration, queries ...fakeQuery) (*sql.DB, *dbtest.Connector) {
+	canned := make([]dbtest.Query, 0, len(queries))
+	for _, q := range queries {
+		q.byTable = make(map[string][][]driver.Value)
+		for _, row := range q.rows {
+			table, _ := row[q.tableColumn].(string)
+			q.byTable[table] = append(q.byTable[table], row)
+		}
+		canned = append(canned, dbtest.Query{Match: q.match, Columns: q.columns, Rows: q.rows, Filter: q.answer})
+	}
+	return dbtest.Open(roundTrip, canned...)
+}
+
+// answer applies the include and exclude patterns that follow the schema name, if any
+func (q *fakeQuery) answer(args []driver.Value) ([][]driver.Value, bool, error) {
+	if len(args) < 3 {
+		return q.rows, true, nil
+	}
+	var include, exclude pq.StringArray
+	if err := include.Scan(args[1]); err != nil {
+		return nil, false, err
+	}
+	if err := exclude.Scan(args[2]); err != nil {
+		return nil, false, err
+	}
+	return q.filter(include, exclude), true, nil
+}
+
+// filter returns the rows of the tables matching the patterns the way tableFilter does. A
+// single table named by a plain pattern is looked up, so that extracting a large catalog
+// one table at a time measures the extractor rather than the fake
+func (q *fakeQuery) filter(include, exclude []string) [][]driver.Value {
+	if len(include) == 1 && len(exclude) == 0 {
+		name := strings.TrimSuffix(strings.TrimPrefix(include[0], "^"), "$")
+		if regexp.QuoteMeta(name) == name {
+			return q.byTable[name]
+		}
+	}
+
+	compile := func(patterns []string) []*regexp.Regexp {
+		compiled := make([]*regexp.Regexp, 0, len(patterns))
+		for _, pattern := range patterns {
+			compiled = append(compiled, regexp.MustCompile(pattern))
+		}
+		return compiled
+	}
+	includeRegexes, excludeRegexes := compile(include), compile(exclude)
+
+	matchAny := func(regexes []*regexp.Regexp, table string) bool {
+		for _, re := range regexes {
+			if re.MatchString(table) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var rows [][]driver.Value
+	for _, row := range q.rows {
+		table := row[q.tableColumn].(string)
+		if (len(include) == 0 || matchAny(includeRegexes, table)) && !matchAny(excludeRegexes, table) {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
@@ -0,0 +1,137 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Richd0tcom/schedrift/internal/config"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type SQLiteConnection struct {
+	db         *sql.DB
+	DriverName string
+}
+
+func NewConnection(ctx context.Context, cfg config.DatabaseConfig) (*SQLiteConnection, error) {
+	path, params, err := databaseFile(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// opening a missing file would create an empty database rather than fail
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open database file: %w", err)
+	}
+
+	// the database is only read, and a writer holding its lock is waited for up to the lock timeout
+	params.Set("mode", "ro")
+	busyTimeout := cfg.LockTimeout.Milliseconds()
+	if busyTimeout <= 0 {
+		busyTimeout = math.MaxInt32
+	}
+	params.Set("_busy_timeout", fmt.Sprint(busyTimeout))
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+
+	// the extraction runs in a single transaction, so there's nothing to spread over more connections
+	db.SetMaxOpenConns(2)
+	db.SetMaxIdleConns(2)
+	db.SetConnMaxIdleTime(time.Hour)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return &SQLiteConnection{db: db}, nil
+}
+
+// databaseFile reads the database file's path and any driver parameters from a URL like
+// sqlite:///tmp/app.db or sqlite://app.db?cache=shared, falling back to the database name
+func databaseFile(cfg config.DatabaseConfig) (string, url.Values, error) {
+	if cfg.Url == "" {
+		if cfg.DatabaseName == "" {
+			return "", nil, fmt.Errorf("a SQLite database file is required (--url sqlite:///path/to/app.db)")
+		}
+		return cfg.DatabaseName, url.Values{}, nil
+	}
+
+	_, location, found := strings.Cut(cfg.Url, "://")
+	if !found {
+		// a plain path, or a file: URI
+		location = strings.TrimPrefix(cfg.Url, "file:")
+	}
+
+	path, query, _ := strings.Cut(location, "?")
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid SQLite connection URL: %w", err)
+	}
+	if path == "" {
+		return "", nil, fmt.Errorf("the SQLite connection URL %s has no database file", cfg.Url)
+	}
+
+	return path, params, nil
+}
+
+func (c *SQLiteConnection) Close() error {
+	return c.db.Close()
+}
+
+func (c *SQLiteConnection) DB() *sql.DB {
+	return c.db
+}
+
+func (c *SQLiteConnection) Query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return c.db.QueryContext(ctx, query, args...)
+}
+
+func (c *SQLiteConnection) QueryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	return c.db.QueryRowContext(ctx, query, args...)
+}
+
+// Exec executes a query without returning any rows
+func (c *SQLiteConnection) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return c.db.ExecContext(ctx, query, args...)
+}
+
+// BeginSnapshot starts a read transaction. Its first query takes a shared lock on the database
+// (or pins the WAL snapshot), so every later query sees the same state. SQLite can't import
+// another transaction's snapshot, so snapshotID must be empty. SQLite has no statement timeout,
+// so queries are only bounded by the context.
+func (c *SQLiteConnection) BeginSnapshot(ctx context.Context, snapshotID string) (*sql.Tx, error) {
+	if snapshotID != "" {
+		return nil, fmt.Errorf("SQLite doesn't support importing snapshot %s", snapshotID)
+	}
+
+	tx, err := c.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	return tx, nil
+}
+
+func (c *SQLiteConnection) GetVersion(ctx context.Context) (string, error) {
+	var version string
+	err := c.db.QueryRowContext(ctx, "SELECT sqlite_version()").Scan(&version)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to get SQLite version: %w", err)
+	}
+	return "SQLite " + version, nil
+}
+
+func (c *SQLiteConnection) GetDriverName() string {
+	return c.DriverName
+}
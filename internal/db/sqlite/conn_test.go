@@ -0,0 +1,47 @@
+package sqlite
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/Richd0tcom/schedrift/internal/config"
+)
+
+func TestDatabaseFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    config.DatabaseConfig
+		path   string
+		params url.Values
+	}{
+		{"absolute path", config.DatabaseConfig{Url: "sqlite:///tmp/app.db"}, "/tmp/app.db", url.Values{}},
+		{"relative path with parameters", config.DatabaseConfig{Url: "sqlite://app.db?x=y"}, "app.db", url.Values{"x": {"y"}}},
+		{"file URI", config.DatabaseConfig{Url: "file:/tmp/app.db?cache=shared"}, "/tmp/app.db", url.Values{"cache": {"shared"}}},
+		{"plain path", config.DatabaseConfig{Url: "data/app.db"}, "data/app.db", url.Values{}},
+		{"database name", config.DatabaseConfig{DatabaseName: "app.db"}, "app.db", url.Values{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, params, err := databaseFile(tt.cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if path != tt.path {
+				t.Errorf("got path %s, want %s", path, tt.path)
+			}
+			if !reflect.DeepEqual(params, tt.params) {
+				t.Errorf("got parameters %v, want %v", params, tt.params)
+			}
+		})
+	}
+}
+
+func TestDatabaseFileMissing(t *testing.T) {
+	for _, cfg := range []config.DatabaseConfig{{}, {Url: "sqlite://"}, {Url: "sqlite://?x=y"}} {
+		if _, _, err := databaseFile(cfg); err == nil {
+			t.Errorf("expected an error for %+v", cfg)
+		}
+	}
+}
@@ -0,0 +1,649 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Richd0tcom/schedrift/internal/config"
+	"github.com/Richd0tcom/schedrift/internal/models"
+	"github.com/Richd0tcom/schedrift/pkg/parser"
+)
+
+// querier runs the extraction queries. It's satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// SQLiteExtractor reads a SQLite database's sqlite_master table and PRAGMAs. The PRAGMAs
+// describe columns, keys and indexes, while constraint names, CHECK constraints, collations
+// and generated columns are only kept in the CREATE statements, which are parsed for them
+type SQLiteExtractor struct {
+	conn     *SQLiteConnection
+	q        querier
+	tables   *config.TableFilter
+	parser   *parser.SQLParser
+	progress models.ProgressFunc
+}
+
+func NewSQLiteExtractor(conn *SQLiteConnection) *SQLiteExtractor {
+	return &SQLiteExtractor{
+		conn:   conn,
+		q:      conn.db,
+		tables: &config.TableFilter{},
+		parser: parser.NewSQLParser(),
+	}
+}
+
+// OnProgress sets a handler that's told how far each extraction has got
+func (e *SQLiteExtractor) OnProgress(handler models.ProgressFunc) {
+	e.progress = handler
+}
+
+// defaultSchemaName names the database's objects when there's no included schema to name them after
+const defaultSchemaName = "main"
+
+// Extract reads the database as a single schema, as SQLite has no schemas. The schema is named
+// after the included schema when there's exactly one, so that it can be checked against a
+// reference written for that schema, and main otherwise
+func (e *SQLiteExtractor) Extract(ctx context.Context, cfg config.SchemaConfig) (*models.DatabaseSchema, error) {
+	tables, err := cfg.TableFilter()
+	if err != nil {
+		return nil, err
+	}
+	e.tables = tables
+
+	tx, err := e.conn.BeginSnapshot(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	e.q = tx
+	defer func() { e.q = e.conn.db }()
+
+	var dbFile string
+	err = e.q.QueryRowContext(ctx, `SELECT file FROM pragma_database_list WHERE name = 'main'`).Scan(&dbFile)
+	if err != nil {
+		return nil, fmt.Errorf("error getting database name: %w", err)
+	}
+
+	schemaName := defaultSchemaName
+	if len(cfg.IncludedSchemas) == 1 {
+		schemaName = cfg.IncludedSchemas[0]
+	}
+
+	dbSchema := &models.DatabaseSchema{
+		Name:    strings.TrimSuffix(dbFile[strings.LastIndex(dbFile, "/")+1:], ".db"),
+		Schemas: []*models.Schema{newSchema(schemaName)},
+	}
+
+	if err = e.extractSchemas(ctx, dbSchema.Schemas); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error closing snapshot transaction: %w", err)
+	}
+
+	return dbSchema, nil
+}
+
+// schemaPart is an independent piece of a schema's extraction, filling its own fields of the schema
+type schemaPart struct {
+	name    string
+	extract func(e *SQLiteExtractor, ctx context.Context, schema *models.Schema) error
+	count   func(schema *models.Schema) int
+}
+
+var schemaParts = []schemaPart{
+	{"tables", (*SQLiteExtractor).extractTables, func(s *models.Schema) int { return len(s.Tables) }},
+	{"views", (*SQLiteExtractor).extractViews, func(s *models.Schema) int { return len(s.Views) }},
+	{"indexes", (*SQLiteExtractor).extractIndexes, func(s *models.Schema) int { return len(s.Indexes) }},
+	{"triggers", (*SQLiteExtractor).extractTriggers, func(s *models.Schema) int { return len(s.Triggers) }},
+}
+
+func newSchema(schemaName string) *models.Schema {
+	return &models.Schema{
+		Name:           schemaName,
+		Tables:         []*models.Table{},
+		Views:          []*models.View{},
+		Triggers:       []*models.Trigger{},
+		Indexes:        []*models.Index{},
+		Functions:      []*models.Function{},
+		Sequences:      []*models.Sequence{},
+		Enums:          []*models.Enum{},
+		Domains:        []*models.Domain{},
+		CompositeTypes: []*models.CompositeType{},
+		Extensions:     []*models.Extension{},
+		Privileges:     []*models.Privilege{},
+		Owners:         []*models.Owner{},
+	}
+}
+
+// extractSchemas fills in every part of the given schemas, one after another
+func (e *SQLiteExtractor) extractSchemas(ctx context.Context, schemas []*models.Schema) error {
+	start := time.Now()
+	tablesDone := 0
+	totals := make(map[string]int)
+
+	// progress is reported in tables, so they're counted up front. Every schema holds the
+	// database's single set of tables
+	tables := 0
+	if e.progress != nil {
+		entries, err := e.objects(ctx, "table")
+		if err != nil {
+			return err
+		}
+		tables = len(schemas) * len(entries)
+	}
+
+	emit := func(ev models.ProgressEvent) {
+		if e.progress == nil {
+			return
+		}
+		ev.TablesDone = tablesDone
+		ev.Tables = tables
+		ev.Elapsed = time.Since(start)
+		e.progress(ev)
+	}
+
+	emit(models.ProgressEvent{Stage: models.ExtractionStarted})
+
+	for _, schema := range schemas {
+		emit(models.ProgressEvent{Stage: models.SchemaStarted, Schema: schema.Name})
+
+		counts := make(map[string]int)
+		for _, part := range schemaParts {
+			if err := part.extract(e, ctx, schema); err != nil {
+				return fmt.Errorf("error extracting %s of schema %s: %w", part.name, schema.Name, err)
+			}
+
+			count := part.count(schema)
+			if part.name == "tables" {
+				tablesDone += count
+			}
+			counts[part.name] = count
+			totals[part.name] += count
+
+			emit(models.ProgressEvent{
+				Stage:      models.ObjectsExtracted,
+				Schema:     schema.Name,
+				ObjectType: part.name,
+				Count:      count,
+			})
+		}
+
+		emit(models.ProgressEvent{Stage: models.SchemaFinished, Schema: schema.Name, Counts: counts})
+	}
+
+	emit(models.ProgressEvent{Stage: models.ExtractionFinished, Counts: totals})
+	return nil
+}
+
+// masterEntry is a row of sqlite_master
+type masterEntry struct {
+	name  string
+	table string
+	sql   string
+}
+
+// objects reads the sqlite_master entries of the given type, leaving out SQLite's internal
+// objects and those on tables excluded by the table filter
+func (e *SQLiteExtractor) objects(ctx context.Context, objectType string) ([]masterEntry, error) {
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT name, tbl_name, COALESCE(sql, '')
+		FROM sqlite_master
+		WHERE type = ? AND name NOT LIKE 'sqlite\_%' ESCAPE '\'
+		ORDER BY tbl_name, name
+	`, objectType)
+
+	if err != nil {
+		return nil, fmt.Errorf("error querying %ss: %w", objectType, err)
+	}
+	defer rows.Close()
+
+	var entries []masterEntry
+	for rows.Next() {
+		var entry masterEntry
+		if err = rows.Scan(&entry.name, &entry.table, &entry.sql); err != nil {
+			return nil, fmt.Errorf("error scanning %s: %w", objectType, err)
+		}
+
+		// a view's tbl_name is the view itself, which the table filter doesn't apply to
+		if objectType != "view" && !e.tables.Match(entry.table) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating %ss: %w", objectType, err)
+	}
+
+	return entries, nil
+}
+
+// parseTable parses a table's CREATE statement, returning nil when it can't be parsed
+func (e *SQLiteExtractor) parseTable(sql string) *models.Table {
+	parsed, err := e.parser.Parse(sql)
+	if err != nil || len(parsed.Tables) != 1 {
+		return nil
+	}
+	return parsed.Tables[0]
+}
+
+func (e *SQLiteExtractor) extractTables(ctx context.Context, schema *models.Schema) error {
+	entries, err := e.objects(ctx, "table")
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		table := &models.Table{
+			Name:        entry.name,
+			Schema:      schema.Name,
+			Columns:     []*models.Column{},
+			Constraints: []*models.Constraint{},
+		}
+
+		// the parsed statement falls back to an empty table, whose constraints get default names
+		parsed := e.parseTable(entry.sql)
+		if parsed == nil {
+			parsed = &models.Table{Name: entry.name}
+		}
+
+		if err = e.extractColumns(ctx, table, parsed); err != nil {
+			return fmt.Errorf("error extracting columns of %s: %w", table.Name, err)
+		}
+
+		if err = e.extractUniqueConstraints(ctx, table, parsed); err != nil {
+			return fmt.Errorf("error extracting unique constraints of %s: %w", table.Name, err)
+		}
+
+		if err = e.extractForeignKeys(ctx, table, parsed); err != nil {
+			return fmt.Errorf("error extracting foreign keys of %s: %w", table.Name, err)
+		}
+
+		// the parsed constraint's RawSQL leaves out its name, so only its name and expression are kept
+		for _, constraint := range parsed.Constraints {
+			if constraint.Type == models.CHECK {
+				table.Constraints = append(table.Constraints, &models.Constraint{
+					Name:      constraint.Name,
+					Type:      models.CHECK,
+					Columns:   constraint.Columns,
+					CheckExpr: constraint.CheckExpr,
+				})
+			}
+		}
+
+		schema.Tables = append(schema.Tables, table)
+	}
+
+	return nil
+}
+
+// extractColumns reads a table's columns and primary key
+func (e *SQLiteExtractor) extractColumns(ctx context.Context, table, parsed *models.Table) error {
+	// table_xinfo also lists generated columns, which table_info leaves out
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT name, type, "notnull", dflt_value, pk, hidden
+		FROM pragma_table_xinfo(?)
+		ORDER BY cid
+	`, table.Name)
+
+	if err != nil {
+		return fmt.Errorf("error querying columns %w", err)
+	}
+	defer rows.Close()
+
+	// pk is the column's position in the primary key, or 0 when it isn't part of it
+	keyColumns := make(map[int]string)
+
+	for rows.Next() {
+		col := &models.Column{}
+		var dataType string
+		var notNull bool
+		var defaultValue *string
+		var pk, hidden int
+		if err = rows.Scan(&col.Name, &dataType, &notNull, &defaultValue, &pk, &hidden); err != nil {
+			return fmt.Errorf("error scanning column: %w", err)
+		}
+
+		// hidden columns of virtual tables aren't part of the table's definition, while
+		// generated columns are hidden 2 when VIRTUAL and 3 when STORED
+		if hidden == 1 {
+			continue
+		}
+
+		col.SetDataType(dataType)
+
+		// SQLite only allows NULL in primary keys for backwards compatibility, which
+		// DDL written for other databases doesn't expect
+		col.IsNullable = !notNull && pk == 0
+		if pk > 0 {
+			keyColumns[pk] = col.Name
+		}
+
+		// dflt_value is the default as written, so string literals keep their quotes
+		if defaultValue != nil {
+			col.DefaultValue = *defaultValue
+		}
+
+		if i := slices.IndexFunc(parsed.Columns, func(c *models.Column) bool { return strings.EqualFold(c.Name, col.Name) }); i != -1 {
+			col.Collation = parsed.Columns[i].Collation
+			col.Generated = parsed.Columns[i].Generated
+		}
+		col.Virtual = hidden == 2
+
+		table.Columns = append(table.Columns, col)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error scanning columns %w", err)
+	}
+
+	if len(keyColumns) > 0 {
+		columns := make([]string, 0, len(keyColumns))
+		for n := 1; n <= len(keyColumns); n++ {
+			columns = append(columns, keyColumns[n])
+		}
+		table.Constraints = append(table.Constraints, &models.Constraint{
+			Name:    constraintName(parsed, models.PRIMARY_KEY, columns),
+			Type:    models.PRIMARY_KEY,
+			Columns: columns,
+		})
+	}
+
+	return nil
+}
+
+// constraintName returns the name the table's DDL gives its constraint of conType on columns,
+// or, as SQLite doesn't require constraints to be named, the name PostgreSQL would give it
+func constraintName(parsed *models.Table, conType models.ConstraintType, columns []string) string {
+	for _, constraint := range parsed.Constraints {
+		if constraint.Type == conType && slices.EqualFunc(constraint.Columns, columns, strings.EqualFold) {
+			return constraint.Name
+		}
+	}
+
+	switch conType {
+	case models.PRIMARY_KEY:
+		return parsed.Name + "_pkey"
+	case models.FOREIGN_KEY:
+		return fmt.Sprintf("%s_%s_fkey", parsed.Name, strings.Join(columns, "_"))
+	default:
+		return fmt.Sprintf("%s_%s_key", parsed.Name, strings.Join(columns, "_"))
+	}
+}
+
+// indexColumns reads the key columns of an index, with expression keys left empty
+func (e *SQLiteExtractor) indexColumns(ctx context.Context, index string) ([]string, []string, error) {
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT COALESCE(name, ''), "desc"
+		FROM pragma_index_xinfo(?)
+		WHERE key
+		ORDER BY seqno
+	`, index)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("error querying columns of index %s: %w", index, err)
+	}
+	defer rows.Close()
+
+	var columns, sortOrders []string
+	for rows.Next() {
+		var column string
+		var desc bool
+		if err = rows.Scan(&column, &desc); err != nil {
+			return nil, nil, fmt.Errorf("error scanning column of index %s: %w", index, err)
+		}
+
+		sortOrder := ""
+		if desc {
+			sortOrder = "DESC"
+		}
+		columns = append(columns, column)
+		sortOrders = append(sortOrders, sortOrder)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating columns of index %s: %w", index, err)
+	}
+
+	return columns, sortOrders, nil
+}
+
+// extractUniqueConstraints reads a table's UNIQUE constraints, which SQLite implements as indexes
+func (e *SQLiteExtractor) extractUniqueConstraints(ctx context.Context, table, parsed *models.Table) error {
+	// origin is u for UNIQUE constraints, as opposed to pk for the primary key and c for CREATE INDEX
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT name
+		FROM pragma_index_list(?)
+		WHERE origin = 'u'
+		ORDER BY seq DESC
+	`, table.Name)
+
+	if err != nil {
+		return fmt.Errorf("error querying unique constraints: %w", err)
+	}
+	defer rows.Close()
+
+	var indexes []string
+	for rows.Next() {
+		var index string
+		if err = rows.Scan(&index); err != nil {
+			return fmt.Errorf("error scanning unique constraint: %w", err)
+		}
+		indexes = append(indexes, index)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating unique constraints: %w", err)
+	}
+	rows.Close()
+
+	for _, index := range indexes {
+		columns, _, err := e.indexColumns(ctx, index)
+		if err != nil {
+			return err
+		}
+
+		table.Constraints = append(table.Constraints, &models.Constraint{
+			Name:    constraintName(parsed, models.UNIQUE, columns),
+			Type:    models.UNIQUE,
+			Columns: columns,
+		})
+	}
+
+	return nil
+}
+
+// fkActions maps foreign_key_list actions to their SQL keywords. NO ACTION is the default and is left empty
+var fkActions = map[string]string{
+	"NO ACTION":   "",
+	"RESTRICT":    "RESTRICT",
+	"CASCADE":     "CASCADE",
+	"SET NULL":    "SET NULL",
+	"SET DEFAULT": "SET DEFAULT",
+}
+
+func (e *SQLiteExtractor) extractForeignKeys(ctx context.Context, table, parsed *models.Table) error {
+	// there's a row per key column, numbered by seq within the foreign key's id
+	rows, err := e.q.QueryContext(ctx, `
+		SELECT id, "table", "from", "to", on_update, on_delete
+		FROM pragma_foreign_key_list(?)
+		ORDER BY id, seq
+	`, table.Name)
+
+	if err != nil {
+		return fmt.Errorf("error querying foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	var foreignKeys []*models.Constraint
+	var refTables []string
+	var refColumns [][]string
+
+	lastID := -1
+	for rows.Next() {
+		var id int
+		var refTable, column, onUpdate, onDelete string
+		var refColumn *string
+		if err = rows.Scan(&id, &refTable, &column, &refColumn, &onUpdate, &onDelete); err != nil {
+			return fmt.Errorf("error scanning foreign key: %w", err)
+		}
+
+		if id != lastID {
+			lastID = id
+			foreignKeys = append(foreignKeys, &models.Constraint{
+				Type:     models.FOREIGN_KEY,
+				OnDelete: fkActions[onDelete],
+				OnUpdate: fkActions[onUpdate],
+			})
+			refTables = append(refTables, refTable)
+			refColumns = append(refColumns, nil)
+		}
+
+		n := len(foreignKeys) - 1
+		foreignKeys[n].Columns = append(foreignKeys[n].Columns, column)
+		// the referenced columns are omitted when the foreign key references the primary key, and are
+		// left out of References too, as they would be in the CREATE TABLE
+		if refColumn != nil {
+			refColumns[n] = append(refColumns[n], *refColumn)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating foreign keys: %w", err)
+	}
+	rows.Close()
+
+	// foreign_key_list lists a table's foreign keys last to first
+	for n := len(foreignKeys) - 1; n >= 0; n-- {
+		fk := foreignKeys[n]
+		fk.Name = constraintName(parsed, models.FOREIGN_KEY, fk.Columns)
+
+		fk.References = refTables[n]
+		if refColumns[n] != nil {
+			fk.References = fmt.Sprintf("%s(%s)", refTables[n], strings.Join(refColumns[n], ", "))
+		}
+
+		table.Constraints = append(table.Constraints, fk)
+	}
+
+	return nil
+}
+
+var viewRegex = regexp.MustCompile(`(?is)^CREATE\s+(?:TEMP\s+|TEMPORARY\s+)?VIEW\s+(?:IF\s+NOT\s+EXISTS\s+)?.+?\s+AS\s+(.+?)\s*;?\s*$`)
+
+func (e *SQLiteExtractor) extractViews(ctx context.Context, schema *models.Schema) error {
+	entries, err := e.objects(ctx, "view")
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		view := &models.View{
+			Schema:     schema.Name,
+			Name:       entry.name,
+			Definition: entry.sql,
+		}
+		if matches := viewRegex.FindStringSubmatch(entry.sql); len(matches) > 1 {
+			view.Definition = matches[1]
+		}
+		schema.Views = append(schema.Views, view)
+	}
+
+	return nil
+}
+
+func (e *SQLiteExtractor) extractIndexes(ctx context.Context, schema *models.Schema) error {
+	// indexes backing primary keys and UNIQUE constraints have no CREATE statement, and are
+	// already described by the table's constraints
+	entries, err := e.objects(ctx, "index")
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.sql == "" {
+			continue
+		}
+
+		index := &models.Index{
+			Name:       entry.name,
+			Schema:     schema.Name,
+			Table:      entry.table,
+			IsValid:    true,
+			Method:     "btree",
+			Definition: entry.sql,
+		}
+
+		var unique bool
+		err := e.q.QueryRowContext(ctx, `SELECT "unique" FROM pragma_index_list(?) WHERE name = ?`, entry.table, entry.name).Scan(&unique)
+		if err != nil {
+			return fmt.Errorf("error querying index %s: %w", entry.name, err)
+		}
+		index.IsUnique = unique
+
+		if index.Columns, index.SortOrders, err = e.indexColumns(ctx, entry.name); err != nil {
+			return err
+		}
+
+		// expression keys and partial index predicates are only kept in the CREATE statement
+		if parsed, err := e.parser.Parse(entry.sql); err == nil && len(parsed.Indexes) == 1 {
+			for n, column := range index.Columns {
+				if column == "" && n < len(parsed.Indexes[0].Columns) {
+					index.Columns[n] = parsed.Indexes[0].Columns[n]
+				}
+			}
+			index.Where = parsed.Indexes[0].Where
+		}
+
+		schema.Indexes = append(schema.Indexes, index)
+	}
+
+	return nil
+}
+
+var triggerRegex = regexp.MustCompile(`(?is)^CREATE\s+(?:TEMP\s+|TEMPORARY\s+)?TRIGGER\s+(?:IF\s+NOT\s+EXISTS\s+)?\S+\s+(?:(BEFORE|AFTER|INSTEAD\s+OF)\s+)?(DELETE|INSERT|UPDATE(?:\s+OF\s+.+?)?)\s+ON\s+\S+\s+(?:FOR\s+EACH\s+ROW\s+)?(?:WHEN\s+(.+?)\s+)?BEGIN\s+(.+?)\s*END\s*;?\s*$`)
+
+func (e *SQLiteExtractor) extractTriggers(ctx context.Context, schema *models.Schema) error {
+	entries, err := e.objects(ctx, "trigger")
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		matches := triggerRegex.FindStringSubmatch(entry.sql)
+		if len(matches) < 5 {
+			return fmt.Errorf("can't parse trigger %s", entry.name)
+		}
+
+		// triggers fire BEFORE unless told otherwise, and always FOR EACH ROW
+		trigger := &models.Trigger{
+			Name:      entry.name,
+			Schema:    schema.Name,
+			Table:     entry.table,
+			Timing:    "BEFORE",
+			Events:    []string{strings.Join(strings.Fields(matches[2]), " ")},
+			ForEach:   "ROW",
+			When:      matches[3],
+			State:     "ENABLED",
+			Statement: strings.TrimSuffix(matches[4], ";"),
+		}
+		if matches[1] != "" {
+			trigger.Timing = strings.ToUpper(strings.Join(strings.Fields(matches[1]), " "))
+		}
+
+		schema.Triggers = append(schema.Triggers, trigger)
+	}
+
+	return nil
+}
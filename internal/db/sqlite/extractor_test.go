@@ -0,0 +1,249 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Richd0tcom/schedrift/internal/config"
+	"github.com/Richd0tcom/schedrift/internal/models"
+)
+
+// appDDL creates a users and a posts table covering the constraints, generated columns,
+// indexes and triggers the extractor reads
+const appDDL = `
+CREATE TABLE users (
+  id INTEGER PRIMARY KEY,
+  email TEXT NOT NULL UNIQUE,
+  age INTEGER,
+  CONSTRAINT users_age_ck CHECK (age >= 0)
+);
+
+CREATE TABLE posts (
+  id INTEGER NOT NULL,
+  user_id INTEGER REFERENCES users (id) ON DELETE CASCADE,
+  title TEXT DEFAULT 'untitled',
+  slug TEXT GENERATED ALWAYS AS (lower(title)) VIRTUAL,
+  size INTEGER GENERATED ALWAYS AS (length(title)) STORED,
+  CONSTRAINT posts_pk PRIMARY KEY (id),
+  CONSTRAINT posts_user_title_uq UNIQUE (user_id, title)
+);
+
+CREATE INDEX idx_posts_live ON posts (user_id DESC) WHERE title <> 'draft';
+CREATE UNIQUE INDEX idx_users_email_lower ON users (lower(email));
+
+CREATE TRIGGER posts_touch AFTER UPDATE OF title ON posts
+BEGIN
+  UPDATE users SET age = age WHERE id = NEW.user_id;
+END;
+`
+
+// connectTemp creates a database from the given DDL in a temporary directory and connects to it
+func connectTemp(t *testing.T, ddl string) *SQLiteConnection {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "app.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("error creating database: %v", err)
+	}
+	if _, err = db.Exec(ddl); err != nil {
+		db.Close()
+		t.Fatalf("error creating schema: %v", err)
+	}
+	db.Close()
+
+	conn, err := NewConnection(context.Background(), config.DatabaseConfig{Url: "sqlite://" + path})
+	if err != nil {
+		t.Fatalf("error connecting: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// extractTemp creates a database from the given DDL in a temporary directory and extracts it
+func extractTemp(t *testing.T, cfg config.SchemaConfig, ddl string) *models.DatabaseSchema {
+	t.Helper()
+
+	dbSchema, err := NewSQLiteExtractor(connectTemp(t, ddl)).Extract(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("extraction failed: %v", err)
+	}
+	return dbSchema
+}
+
+func findTable(t *testing.T, schema *models.Schema, name string) *models.Table {
+	t.Helper()
+	for _, table := range schema.Tables {
+		if table.Name == name {
+			return table
+		}
+	}
+	t.Fatalf("table %s wasn't extracted", name)
+	return nil
+}
+
+func findColumn(t *testing.T, table *models.Table, name string) *models.Column {
+	t.Helper()
+	for _, col := range table.Columns {
+		if col.Name == name {
+			return col
+		}
+	}
+	t.Fatalf("column %s.%s wasn't extracted", table.Name, name)
+	return nil
+}
+
+func TestExtractSchemaName(t *testing.T) {
+	dbSchema := extractTemp(t, config.SchemaConfig{}, appDDL)
+	if dbSchema.Name != "app" || len(dbSchema.Schemas) != 1 || dbSchema.Schemas[0].Name != "main" {
+		t.Fatalf("expected database app with schema main, got %s with %+v", dbSchema.Name, dbSchema.Schemas)
+	}
+
+	// a single included schema names the database's objects
+	dbSchema = extractTemp(t, config.SchemaConfig{IncludedSchemas: []string{"public"}}, appDDL)
+	if dbSchema.Schemas[0].Name != "public" || findTable(t, dbSchema.Schemas[0], "users").Schema != "public" {
+		t.Fatalf("expected the objects to be named after schema public, got %+v", dbSchema.Schemas[0])
+	}
+}
+
+func TestExtractColumns(t *testing.T) {
+	schema := extractTemp(t, config.SchemaConfig{}, appDDL).Schemas[0]
+
+	users := findTable(t, schema, "users")
+	// the primary key isn't nullable, even though SQLite would allow it
+	if id := findColumn(t, users, "id"); id.IsNullable {
+		t.Errorf("users.id should not be nullable")
+	}
+	if email := findColumn(t, users, "email"); email.IsNullable {
+		t.Errorf("users.email should not be nullable")
+	}
+
+	posts := findTable(t, schema, "posts")
+	if title := findColumn(t, posts, "title"); title.DefaultValue != "'untitled'" || !title.IsNullable {
+		t.Errorf("unexpected posts.title: %+v", title)
+	}
+	if slug := findColumn(t, posts, "slug"); slug.Generated != "lower(title)" || !slug.Virtual {
+		t.Errorf("expected posts.slug to be a virtual generated column, got %+v", slug)
+	}
+	if size := findColumn(t, posts, "size"); size.Generated != "length(title)" || size.Virtual {
+		t.Errorf("expected posts.size to be a stored generated column, got %+v", size)
+	}
+
+	var names []string
+	for _, col := range posts.Columns {
+		names = append(names, col.Name)
+	}
+	if want := []string{"id", "user_id", "title", "slug", "size"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got columns %v, want %v", names, want)
+	}
+}
+
+func TestExtractConstraints(t *testing.T) {
+	schema := extractTemp(t, config.SchemaConfig{}, appDDL).Schemas[0]
+
+	tests := map[string][]*models.Constraint{
+		"users": {
+			{Name: "users_pkey", Type: models.PRIMARY_KEY, Columns: []string{"id"}},
+			{Name: "users_email_key", Type: models.UNIQUE, Columns: []string{"email"}},
+			{Name: "users_age_ck", Type: models.CHECK, CheckExpr: "age >= 0"},
+		},
+		"posts": {
+			{Name: "posts_pk", Type: models.PRIMARY_KEY, Columns: []string{"id"}},
+			{Name: "posts_user_title_uq", Type: models.UNIQUE, Columns: []string{"user_id", "title"}},
+			{Name: "posts_user_id_fkey", Type: models.FOREIGN_KEY, Columns: []string{"user_id"}, References: "users(id)", OnDelete: "CASCADE"},
+		},
+	}
+
+	for tableName, want := range tests {
+		table := findTable(t, schema, tableName)
+		if !reflect.DeepEqual(table.Constraints, want) {
+			t.Errorf("constraints of %s:", tableName)
+			for _, constraint := range table.Constraints {
+				t.Errorf("  got %+v", *constraint)
+			}
+		}
+	}
+}
+
+func TestDumpKeepsNamesAndDefaults(t *testing.T) {
+	schema := extractTemp(t, config.SchemaConfig{}, appDDL).Schemas[0]
+
+	dump := findTable(t, schema, "users").ToSQL() + findTable(t, schema, "posts").ToSQL()
+	for _, want := range []string{"CONSTRAINT users_age_ck CHECK (age >= 0)", "DEFAULT 'untitled'"} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("expected the dump to contain %s, got:\n%s", want, dump)
+		}
+	}
+}
+
+func TestExtractIndexesAndTriggers(t *testing.T) {
+	schema := extractTemp(t, config.SchemaConfig{}, appDDL).Schemas[0]
+
+	// the indexes backing the keys and UNIQUE constraints are described by the constraints
+	if len(schema.Indexes) != 2 {
+		t.Fatalf("expected 2 indexes, got %+v", schema.Indexes)
+	}
+
+	live := schema.Indexes[0]
+	if live.Name != "idx_posts_live" || live.Table != "posts" || live.IsUnique ||
+		!reflect.DeepEqual(live.Columns, []string{"user_id"}) || !reflect.DeepEqual(live.SortOrders, []string{"DESC"}) ||
+		live.Where != "title <> 'draft'" {
+		t.Errorf("unexpected partial index: %+v", live)
+	}
+
+	lower := schema.Indexes[1]
+	if lower.Name != "idx_users_email_lower" || lower.Table != "users" || !lower.IsUnique ||
+		!reflect.DeepEqual(lower.Columns, []string{"lower(email)"}) {
+		t.Errorf("unexpected expression index: %+v", lower)
+	}
+
+	want := []*models.Trigger{{
+		Name:      "posts_touch",
+		Schema:    "main",
+		Table:     "posts",
+		Events:    []string{"UPDATE OF title"},
+		Timing:    "AFTER",
+		ForEach:   "ROW",
+		State:     "ENABLED",
+		Statement: "UPDATE users SET age = age WHERE id = NEW.user_id",
+	}}
+	if !reflect.DeepEqual(schema.Triggers, want) {
+		t.Errorf("unexpected triggers: %+v", schema.Triggers[0])
+	}
+}
+
+func TestExtractTableFilter(t *testing.T) {
+	cfg := config.SchemaConfig{ExcludedTables: []string{"users"}}
+	schema := extractTemp(t, cfg, appDDL).Schemas[0]
+
+	if len(schema.Tables) != 1 || schema.Tables[0].Name != "posts" {
+		t.Fatalf("expected only posts to be extracted, got %+v", schema.Tables)
+	}
+	// the expression index on users goes with its table
+	if len(schema.Indexes) != 1 || schema.Indexes[0].Table != "posts" {
+		t.Errorf("expected only the index on posts, got %+v", schema.Indexes)
+	}
+}
+
+func TestExtractProgress(t *testing.T) {
+	e := NewSQLiteExtractor(connectTemp(t, appDDL))
+
+	var events []models.ProgressEvent
+	e.OnProgress(func(ev models.ProgressEvent) { events = append(events, ev) })
+
+	if _, err := e.Extract(context.Background(), config.SchemaConfig{ExcludedTables: []string{"posts"}}); err != nil {
+		t.Fatalf("extraction failed: %v", err)
+	}
+
+	// the tables are counted before any is extracted
+	if first := events[0]; first.Stage != models.ExtractionStarted || first.Tables != 1 || first.TablesDone != 0 {
+		t.Errorf("unexpected first event: %+v", first)
+	}
+	if last := events[len(events)-1]; last.Stage != models.ExtractionFinished || last.TablesDone != 1 || last.Counts["indexes"] != 1 {
+		t.Errorf("unexpected last event: %+v", last)
+	}
+}
@@ -2,13 +2,15 @@ package diff
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/Richd0tcom/schedrift/internal/models"
 )
 
 //TODO: concurrent comparison
-//TODO: sort changes by severity
 //TODO: add support for views, procedures, functions, events, sequences, indexes, triggers
 
 type SeverityLevel string
@@ -69,13 +71,23 @@ func (d *Diff) HasChanges() bool {
 	return len(d.Changes) > 0
 }
 
-func (d *Diff) HasSeverity(minSeverity SeverityLevel) bool {
-	severityMap := map[SeverityLevel]int{
-		None:   0,
-		Low:    1,
-		Medium: 2,
-		High:   3,
+var severityMap = map[SeverityLevel]int{
+	None:   0,
+	Low:    1,
+	Medium: 2,
+	High:   3,
+}
+
+// ParseSeverity converts a user supplied severity name (e.g. from a CLI flag) into a SeverityLevel
+func ParseSeverity(s string) (SeverityLevel, error) {
+	level := SeverityLevel(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := severityMap[level]; !ok {
+		return None, fmt.Errorf("invalid severity %q (expected none, low, medium or high)", s)
 	}
+	return level, nil
+}
+
+func (d *Diff) HasSeverity(minSeverity SeverityLevel) bool {
 	minLevel := severityMap[minSeverity]
 
 	for _, change := range d.Changes {
@@ -87,6 +99,68 @@ func (d *Diff) HasSeverity(minSeverity SeverityLevel) bool {
 	return false
 }
 
+// CountSeverity returns the number of changes at or above minSeverity
+func (d *Diff) CountSeverity(minSeverity SeverityLevel) int {
+	count := 0
+	for _, change := range d.Changes {
+		if severityMap[change.Severity] >= severityMap[minSeverity] {
+			count++
+		}
+	}
+	return count
+}
+
+// SortChanges orders changes by severity (highest first), then by object type and name
+// so that output is stable between runs
+func (d *Diff) SortChanges() {
+	sort.SliceStable(d.Changes, func(i, j int) bool {
+		a, b := d.Changes[i], d.Changes[j]
+		if severityMap[a.Severity] != severityMap[b.Severity] {
+			return severityMap[a.Severity] > severityMap[b.Severity]
+		}
+		if a.ObjectType != b.ObjectType {
+			return a.ObjectType < b.ObjectType
+		}
+		if a.ParentName != b.ParentName {
+			return a.ParentName < b.ParentName
+		}
+		if a.ObjectName != b.ObjectName {
+			return a.ObjectName < b.ObjectName
+		}
+		return a.Description < b.Description
+	})
+}
+
+// ToText renders the diff as a human readable report
+func (d *Diff) ToText() string {
+	var sb strings.Builder
+
+	if !d.HasChanges() {
+		sb.WriteString("No schema differences found\n")
+		return sb.String()
+	}
+
+	d.SortChanges()
+
+	sb.WriteString(fmt.Sprintf("Found %d change(s):\n\n", len(d.Changes)))
+	for _, change := range d.Changes {
+		sb.WriteString(fmt.Sprintf("  [%-6s] %-8s %s\n", strings.ToUpper(string(change.Severity)), change.Type, change.Description))
+	}
+
+	keys := make([]string, 0, len(d.Summary))
+	for key := range d.Summary {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	sb.WriteString("\nSummary:\n")
+	for _, key := range keys {
+		sb.WriteString(fmt.Sprintf("  %s: %d\n", key, d.Summary[key]))
+	}
+
+	return sb.String()
+}
+
 func isBreakingTypeChange(oldType, newType string) bool {
 	
 	oldType = strings.ToLower(strings.TrimSpace(oldType))
@@ -122,6 +196,35 @@ func isBreakingTypeChange(oldType, newType string) bool {
 	return true
 }
 
+// isBreakingColumnTypeChange reports whether existing values of src may not fit tgt's type,
+// taking the declared length, precision, scale and array dimensions into account
+func isBreakingColumnTypeChange(src, tgt *models.Column) bool {
+	if src.ArrayDims != tgt.ArrayDims {
+		return true
+	}
+
+	if isBreakingTypeChange(src.BaseType(), tgt.BaseType()) {
+		return true
+	}
+
+	// a length or precision of 0 means the type is unconstrained
+	if tgt.Length > 0 && (src.Length == 0 || tgt.Length < src.Length) {
+		return true
+	}
+
+	if tgt.Precision > 0 {
+		if src.Precision == 0 {
+			return true
+		}
+		// numeric(p,s) keeps p-s digits before the decimal point and rounds to s after it
+		if tgt.Scale < src.Scale || tgt.Precision-tgt.Scale < src.Precision-src.Scale {
+			return true
+		}
+	}
+
+	return false
+}
+
 func isNarrowingNumericChange(oldType, newType string) bool {
 	typeRank := map[string]int{
 		"smallint":        1,
@@ -161,13 +264,14 @@ func compareColumns(diff *Diff, tableName string, sourceTable, targetTable *mode
 
 			severity := Medium
 
-			if col.IsNullable {
-				
+			if !col.IsNullable && col.DefaultValue == "" && col.Identity == "" && col.Generated == "" {
+				// existing rows can't satisfy a NOT NULL column that nothing fills in
 				severity = High
 			}
 			diff.AddChange(Change{
 				Type:        Added,
 				ObjectType:  "column",
+				ObjectName:  colName,
 				ParentName:  tableName, //TODO: see if name can be gotten from table w/o consequence
 				Severity:    severity,
 				Description: fmt.Sprintf("Column %s.%s was added", tableName, colName),
@@ -188,6 +292,7 @@ func compareColumns(diff *Diff, tableName string, sourceTable, targetTable *mode
 			diff.AddChange(Change{
 				Type:        Removed,
 				ObjectType:  "column",
+				ObjectName:  colName,
 				ParentName:  tableName,
 				Severity:    High, 
 				Description: fmt.Sprintf("Column %s.%s was removed", tableName, colName),
@@ -208,7 +313,7 @@ func compareColumns(diff *Diff, tableName string, sourceTable, targetTable *mode
 		if srcCol.DataType != tgtCol.DataType {
 			severity := Medium
 
-			if isBreakingTypeChange(srcCol.DataType, tgtCol.DataType) {
+			if isBreakingColumnTypeChange(srcCol, tgtCol) {
 				severity = High
 			}
 
@@ -227,6 +332,22 @@ func compareColumns(diff *Diff, tableName string, sourceTable, targetTable *mode
 
 		}
 
+		if srcCol.Collation != tgtCol.Collation {
+			// changes how values sort and compare, and rebuilds the indexes on the column
+			diff.AddChange(Change{
+				Type:        Modified,
+				ObjectType:  "column",
+				ObjectName:  srcColName,
+				ParentName:  tableName,
+				Severity:    Medium,
+				Description: fmt.Sprintf("Column %s.%s collation changed from %s to %s", tableName, srcColName, collationOrDefault(srcCol.Collation), collationOrDefault(tgtCol.Collation)),
+				Details: map[string]any{
+					"old_collation": srcCol.Collation,
+					"new_collation": tgtCol.Collation,
+				},
+			})
+		}
+
 		if srcCol.IsNullable != tgtCol.IsNullable {
 			severity := Low
 			nullChange := "made nullable"
@@ -276,76 +397,1626 @@ func compareColumns(diff *Diff, tableName string, sourceTable, targetTable *mode
 				},
 			})
 		}
+
+		if normalizeExpr(srcCol.Generated) != normalizeExpr(tgtCol.Generated) {
+			// a new or changed expression rewrites every row and rejects inserts that set the
+			// column, while dropping it just leaves the current values in place
+			severity := High
+			if tgtCol.Generated == "" {
+				severity = Medium
+			}
+
+			diff.AddChange(Change{
+				Type:        Modified,
+				ObjectType:  "column",
+				ObjectName:  srcColName,
+				ParentName:  tableName,
+				Severity:    severity,
+				Description: fmt.Sprintf("Column %s.%s generation expression changed from %s to %s", tableName, srcColName, orNone(srcCol.Generated), orNone(tgtCol.Generated)),
+				Details: map[string]any{
+					"old_generated": srcCol.Generated,
+					"new_generated": tgtCol.Generated,
+				},
+			})
+		} else if srcCol.Generated != "" && srcCol.Virtual != tgtCol.Virtual {
+			// switching between the two means dropping and re-adding the column, which
+			// rewrites the table when it becomes STORED
+			diff.AddChange(Change{
+				Type:        Modified,
+				ObjectType:  "column",
+				ObjectName:  srcColName,
+				ParentName:  tableName,
+				Severity:    Medium,
+				Description: fmt.Sprintf("Column %s.%s generated column changed from %s to %s", tableName, srcColName, generatedKind(srcCol), generatedKind(tgtCol)),
+				Details: map[string]any{
+					"old_kind": generatedKind(srcCol),
+					"new_kind": generatedKind(tgtCol),
+				},
+			})
+		}
+
+		if srcCol.Identity != tgtCol.Identity {
+			severity := Medium
+			if tgtCol.Identity == "ALWAYS" {
+				// inserts that supply their own value are rejected by GENERATED ALWAYS
+				severity = High
+			}
+
+			diff.AddChange(Change{
+				Type:        Modified,
+				ObjectType:  "column",
+				ObjectName:  srcColName,
+				ParentName:  tableName,
+				Severity:    severity,
+				Description: fmt.Sprintf("Column %s.%s identity changed from %s to %s", tableName, srcColName, identityOrNone(srcCol.Identity), identityOrNone(tgtCol.Identity)),
+				Details: map[string]any{
+					"old_identity": srcCol.Identity,
+					"new_identity": tgtCol.Identity,
+				},
+			})
+		}
 	}
 
 }
 
-func compareTables(diff *Diff, src, target *models.Schema) {
-	sourceTables := make(map[string]*models.Table)
-	targetTables := make(map[string]*models.Table)
+func collationOrDefault(collation string) string {
+	if collation == "" {
+		return "default"
+	}
+	return collation
+}
 
-	for _, t := range src.Tables {
-		sourceTables[t.Name] = t
+func generatedKind(col *models.Column) string {
+	if col.Virtual {
+		return "VIRTUAL"
 	}
+	return "STORED"
+}
 
-	for _, t := range target.Tables {
-		targetTables[t.Name] = t
+func identityOrNone(identity string) string {
+	if identity == "" {
+		return "none"
+	}
+	return "GENERATED " + identity + " AS IDENTITY"
+}
+
+func compareConstraints(diff *Diff, tableName string, sourceTable, targetTable *models.Table) {
+	srcCons := make(map[string]*models.Constraint)
+	targetCons := make(map[string]*models.Constraint)
+
+	for _, c := range sourceTable.Constraints {
+		srcCons[c.Name] = c
 	}
 
-	//removed tables
-	for tableName, srcTable := range sourceTables {
-		if _, exists := targetTables[tableName]; !exists {
+	for _, c := range targetTable.Constraints {
+		targetCons[c.Name] = c
+	}
+
+	for conName, con := range targetCons {
+		if _, exists := srcCons[conName]; !exists {
+			// new constraints may be rejected by existing data
+			diff.AddChange(Change{
+				Type:        Added,
+				ObjectType:  "constraint",
+				ObjectName:  conName,
+				ParentName:  tableName,
+				Severity:    Medium,
+				Description: fmt.Sprintf("%s constraint %s was added to %s", con.Type, conName, tableName),
+				Details: map[string]any{
+					"type":    con.Type,
+					"columns": con.Columns,
+				},
+			})
+		}
+	}
+
+	for conName, con := range srcCons {
+		if _, exists := targetCons[conName]; !exists {
 			diff.AddChange(Change{
 				Type:        Removed,
-				ObjectType:  "table",
-				ObjectName:  tableName,
+				ObjectType:  "constraint",
+				ObjectName:  conName,
+				ParentName:  tableName,
 				Severity:    High,
-				Description: fmt.Sprintf("Table %s was removed", tableName),
+				Description: fmt.Sprintf("%s constraint %s was removed from %s", con.Type, conName, tableName),
 				Details: map[string]any{
-					"columns": len(srcTable.Columns),
+					"type":    con.Type,
+					"columns": con.Columns,
 				},
 			})
 		}
 	}
 
-	//added tables
-	for tableName, targetTable := range targetTables {
-		if _, exists := sourceTables[tableName]; !exists {
+	for conName, srcCon := range srcCons {
+		tgtCon, exists := targetCons[conName]
+		if !exists {
+			continue
+		}
+
+		modified := func(severity SeverityLevel, what string, oldValue, newValue any) {
+			diff.AddChange(Change{
+				Type:        Modified,
+				ObjectType:  "constraint",
+				ObjectName:  conName,
+				ParentName:  tableName,
+				Severity:    severity,
+				Description: fmt.Sprintf("Constraint %s on %s %s changed from %v to %v", conName, tableName, what, oldValue, newValue),
+				Details: map[string]any{
+					"old_" + strings.ReplaceAll(what, " ", "_"): oldValue,
+					"new_" + strings.ReplaceAll(what, " ", "_"): newValue,
+				},
+			})
+		}
+
+		if srcCon.Type != tgtCon.Type {
+			modified(High, "type", srcCon.Type, tgtCon.Type)
+			continue
+		}
+
+		// check constraints are compared by expression, as parsed DDL doesn't know which columns they touch
+		if srcCon.Type != models.CHECK && strings.Join(srcCon.Columns, ",") != strings.Join(tgtCon.Columns, ",") {
+			modified(High, "columns", strings.Join(srcCon.Columns, ", "), strings.Join(tgtCon.Columns, ", "))
+		}
+
+		if srcCon.Type == models.FOREIGN_KEY {
+			if normalizeExpr(srcCon.References) != normalizeExpr(tgtCon.References) {
+				modified(High, "references", srcCon.References, tgtCon.References)
+			}
+			if actionOrDefault(srcCon.OnDelete) != actionOrDefault(tgtCon.OnDelete) {
+				modified(Medium, "on delete", actionOrDefault(srcCon.OnDelete), actionOrDefault(tgtCon.OnDelete))
+			}
+			if actionOrDefault(srcCon.OnUpdate) != actionOrDefault(tgtCon.OnUpdate) {
+				modified(Medium, "on update", actionOrDefault(srcCon.OnUpdate), actionOrDefault(tgtCon.OnUpdate))
+			}
+		}
+
+		if srcCon.Deferrable != tgtCon.Deferrable || srcCon.InitiallyDeferred != tgtCon.InitiallyDeferred {
+			modified(Low, "deferrability", deferrability(srcCon), deferrability(tgtCon))
+		}
+
+		// expressions are only comparable when both sides carry one
+		if srcCon.CheckExpr != "" && tgtCon.CheckExpr != "" && normalizeExpr(srcCon.CheckExpr) != normalizeExpr(tgtCon.CheckExpr) {
+			modified(Medium, "check expression", srcCon.CheckExpr, tgtCon.CheckExpr)
+		}
+
+		if srcCon.Definition != "" && tgtCon.Definition != "" && normalizeExpr(srcCon.Definition) != normalizeExpr(tgtCon.Definition) {
+			modified(Medium, "definition", srcCon.Definition, tgtCon.Definition)
+		}
+	}
+}
+
+// normalizeExpr lowercases an SQL expression and strips whitespace and parentheses so that
+// "(price > 0)" and "price>0" compare equal
+func normalizeExpr(expr string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\n', '\r', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.ToLower(expr))
+}
+
+func actionOrDefault(action string) string {
+	if action == "" {
+		return "NO ACTION"
+	}
+	return action
+}
+
+func deferrability(c *models.Constraint) string {
+	switch {
+	case c.Deferrable && c.InitiallyDeferred:
+		return "DEFERRABLE INITIALLY DEFERRED"
+	case c.Deferrable:
+		return "DEFERRABLE INITIALLY IMMEDIATE"
+	default:
+		return "NOT DEFERRABLE"
+	}
+}
+
+func compareIndexes(diff *Diff, src, target *models.Schema) {
+	srcIndexes := make(map[string]*models.Index)
+	targetIndexes := make(map[string]*models.Index)
+
+	for _, i := range src.Indexes {
+		srcIndexes[i.Name] = i
+	}
+
+	for _, i := range target.Indexes {
+		targetIndexes[i.Name] = i
+	}
+
+	for indexName, index := range targetIndexes {
+		if !index.IsValid {
+			// left behind by a failed CREATE INDEX CONCURRENTLY: it is maintained on writes but never used
+			diff.AddChange(Change{
+				Type:        Modified,
+				ObjectType:  "index",
+				ObjectName:  indexName,
+				ParentName:  index.Table,
+				Severity:    High,
+				Description: fmt.Sprintf("Index %s on %s is invalid", indexName, index.Table),
+				Details: map[string]any{
+					"valid": false,
+				},
+			})
+		}
+
+		if _, exists := srcIndexes[indexName]; !exists {
 			diff.AddChange(Change{
 				Type:        Added,
-				ObjectType:  "table",
-				ObjectName:  tableName,
-				ParentName:  "",
+				ObjectType:  "index",
+				ObjectName:  indexName,
+				ParentName:  index.Table,
 				Severity:    Low,
-				Description: fmt.Sprintf("Table %s was added", tableName),
+				Description: fmt.Sprintf("Index %s on %s was added", indexName, index.Table),
 				Details: map[string]any{
-					"columns": len(targetTable.Columns),
+					"columns": index.Columns,
+					"unique":  index.IsUnique,
 				},
 			})
 		}
 	}
 
-	for tableName, srcTable := range sourceTables {
-		targetTable, exists := targetTables[tableName]
+	for indexName, index := range srcIndexes {
+		if _, exists := targetIndexes[indexName]; !exists {
+			severity := Medium
+			if index.IsUnique {
+				// dropping a unique index also drops the uniqueness guarantee
+				severity = High
+			}
+
+			diff.AddChange(Change{
+				Type:        Removed,
+				ObjectType:  "index",
+				ObjectName:  indexName,
+				ParentName:  index.Table,
+				Severity:    severity,
+				Description: fmt.Sprintf("Index %s on %s was removed", indexName, index.Table),
+				Details: map[string]any{
+					"columns": index.Columns,
+					"unique":  index.IsUnique,
+				},
+			})
+		}
+	}
+
+	for indexName, srcIndex := range srcIndexes {
+		tgtIndex, exists := targetIndexes[indexName]
 		if !exists {
 			continue
 		}
 
-		compareColumns(diff, tableName, srcTable, targetTable)
+		modified := func(severity SeverityLevel, what string, oldValue, newValue any) {
+			diff.AddChange(Change{
+				Type:        Modified,
+				ObjectType:  "index",
+				ObjectName:  indexName,
+				ParentName:  tgtIndex.Table,
+				Severity:    severity,
+				Description: fmt.Sprintf("Index %s on %s %s changed from %v to %v", indexName, tgtIndex.Table, what, oldValue, newValue),
+				Details: map[string]any{
+					"old_" + strings.ReplaceAll(what, " ", "_"): oldValue,
+					"new_" + strings.ReplaceAll(what, " ", "_"): newValue,
+				},
+			})
+		}
+
+		if srcIndex.IsUnique != tgtIndex.IsUnique {
+			modified(High, "uniqueness", srcIndex.IsUnique, tgtIndex.IsUnique)
+		}
 
-		//compare indexes
-		//compare constraints
-		//compare triggers
-		//compare views
-		//compare procedures
-		//compare functions
-		//compare events
-		//compare sequences
+		if !strings.EqualFold(srcIndex.Method, tgtIndex.Method) {
+			modified(Medium, "method", srcIndex.Method, tgtIndex.Method)
+		}
+
+		if normalizeExprList(srcIndex.Columns) != normalizeExprList(tgtIndex.Columns) {
+			modified(Medium, "columns", strings.Join(srcIndex.Columns, ", "), strings.Join(tgtIndex.Columns, ", "))
+		}
+
+		if normalizeExprList(srcIndex.Include) != normalizeExprList(tgtIndex.Include) {
+			modified(Medium, "included columns", strings.Join(srcIndex.Include, ", "), strings.Join(tgtIndex.Include, ", "))
+		}
+
+		if normalizeExprList(srcIndex.OpClasses) != normalizeExprList(tgtIndex.OpClasses) {
+			modified(Medium, "operator classes", strings.Join(srcIndex.OpClasses, ", "), strings.Join(tgtIndex.OpClasses, ", "))
+		}
+
+		if normalizeExprList(srcIndex.SortOrders) != normalizeExprList(tgtIndex.SortOrders) {
+			modified(Low, "sort order", strings.Join(srcIndex.SortOrders, ", "), strings.Join(tgtIndex.SortOrders, ", "))
+		}
+
+		if normalizeExpr(srcIndex.Where) != normalizeExpr(tgtIndex.Where) {
+			modified(Medium, "predicate", srcIndex.Where, tgtIndex.Where)
+		}
+	}
+}
+
+func compareTriggers(diff *Diff, src, target *models.Schema) {
+	srcTriggers := make(map[string]*models.Trigger)
+	targetTriggers := make(map[string]*models.Trigger)
+
+	// trigger names are only unique per table
+	for _, t := range src.Triggers {
+		srcTriggers[t.Table+"."+t.Name] = t
+	}
+
+	for _, t := range target.Triggers {
+		targetTriggers[t.Table+"."+t.Name] = t
+	}
+
+	for key, trigger := range targetTriggers {
+		if _, exists := srcTriggers[key]; !exists {
+			diff.AddChange(Change{
+				Type:        Added,
+				ObjectType:  "trigger",
+				ObjectName:  trigger.Name,
+				ParentName:  trigger.Table,
+				Severity:    Medium,
+				Description: fmt.Sprintf("Trigger %s on %s was added", trigger.Name, trigger.Table),
+				Details: map[string]any{
+					"events":   trigger.Events,
+					"function": trigger.Statement,
+				},
+			})
+		}
+	}
+
+	for key, trigger := range srcTriggers {
+		if _, exists := targetTriggers[key]; !exists {
+			diff.AddChange(Change{
+				Type:        Removed,
+				ObjectType:  "trigger",
+				ObjectName:  trigger.Name,
+				ParentName:  trigger.Table,
+				Severity:    High,
+				Description: fmt.Sprintf("Trigger %s on %s was removed", trigger.Name, trigger.Table),
+				Details: map[string]any{
+					"events":   trigger.Events,
+					"function": trigger.Statement,
+				},
+			})
+		}
+	}
+
+	for key, srcTrigger := range srcTriggers {
+		tgtTrigger, exists := targetTriggers[key]
+		if !exists {
+			continue
+		}
+
+		modified := func(severity SeverityLevel, what string, oldValue, newValue any) {
+			diff.AddChange(Change{
+				Type:        Modified,
+				ObjectType:  "trigger",
+				ObjectName:  tgtTrigger.Name,
+				ParentName:  tgtTrigger.Table,
+				Severity:    severity,
+				Description: fmt.Sprintf("Trigger %s on %s %s changed from %v to %v", tgtTrigger.Name, tgtTrigger.Table, what, oldValue, newValue),
+				Details: map[string]any{
+					"old_" + strings.ReplaceAll(what, " ", "_"): oldValue,
+					"new_" + strings.ReplaceAll(what, " ", "_"): newValue,
+				},
+			})
+		}
+
+		if stateOrDefault(srcTrigger.State) != stateOrDefault(tgtTrigger.State) {
+			severity := Medium
+			if stateOrDefault(tgtTrigger.State) == "DISABLED" {
+				// a disabled trigger silently stops enforcing whatever it was written for
+				severity = High
+			}
+			modified(severity, "state", stateOrDefault(srcTrigger.State), stateOrDefault(tgtTrigger.State))
+		}
+
+		if srcTrigger.Timing != tgtTrigger.Timing {
+			modified(Medium, "timing", srcTrigger.Timing, tgtTrigger.Timing)
+		}
+
+		if !sameEvents(srcTrigger.Events, tgtTrigger.Events) {
+			modified(Medium, "events", strings.Join(srcTrigger.Events, " OR "), strings.Join(tgtTrigger.Events, " OR "))
+		}
+
+		if srcTrigger.ForEach != tgtTrigger.ForEach {
+			modified(Medium, "level", "FOR EACH "+srcTrigger.ForEach, "FOR EACH "+tgtTrigger.ForEach)
+		}
+
+		if normalizeExpr(srcTrigger.When) != normalizeExpr(tgtTrigger.When) {
+			modified(Medium, "condition", srcTrigger.When, tgtTrigger.When)
+		}
+
+		if normalizeExpr(srcTrigger.Statement) != normalizeExpr(tgtTrigger.Statement) {
+			modified(High, "function", srcTrigger.Statement, tgtTrigger.Statement)
+		}
+	}
+}
+
+func stateOrDefault(state string) string {
+	if state == "" {
+		return "ENABLED"
+	}
+	return state
+}
+
+// sameEvents compares trigger events regardless of the order they were declared in
+func sameEvents(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	counts := make(map[string]int)
+	for _, event := range a {
+		counts[normalizeExpr(event)]++
+	}
+	for _, event := range b {
+		counts[normalizeExpr(event)]--
+	}
+	for _, count := range counts {
+		if count != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// normalizeExprList normalizes each expression in a list; empty entries are kept so positions still line up
+func normalizeExprList(exprs []string) string {
+	normalized := make([]string, 0, len(exprs))
+	for _, expr := range exprs {
+		normalized = append(normalized, normalizeExpr(expr))
+	}
+	return strings.TrimRight(strings.Join(normalized, ","), ",")
+}
+
+func compareTables(diff *Diff, src, target *models.Schema) {
+	sourceTables := make(map[string]*models.Table)
+	targetTables := make(map[string]*models.Table)
+
+	for _, t := range src.Tables {
+		sourceTables[t.Name] = t
+	}
+
+	for _, t := range target.Tables {
+		targetTables[t.Name] = t
+	}
+
+	//removed tables
+	for tableName, srcTable := range sourceTables {
+		if _, exists := targetTables[tableName]; !exists {
+			if srcTable.IsPartition() {
+				// rows in its range have nowhere to go unless there's a default partition
+				diff.AddChange(Change{
+					Type:        Removed,
+					ObjectType:  "partition",
+					ObjectName:  tableName,
+					ParentName:  srcTable.PartitionOf,
+					Severity:    High,
+					Description: fmt.Sprintf("Partition %s of %s was removed", tableName, srcTable.PartitionOf),
+					Details: map[string]any{
+						"bound": srcTable.PartitionBound,
+					},
+				})
+				continue
+			}
+
+			diff.AddChange(Change{
+				Type:        Removed,
+				ObjectType:  "table",
+				ObjectName:  tableName,
+				Severity:    High,
+				Description: fmt.Sprintf("Table %s was removed", tableName),
+				Details: map[string]any{
+					"columns": len(srcTable.Columns),
+				},
+			})
+		}
+	}
+
+	//added tables
+	for tableName, targetTable := range targetTables {
+		if _, exists := sourceTables[tableName]; !exists {
+			if targetTable.IsPartition() {
+				diff.AddChange(Change{
+					Type:        Added,
+					ObjectType:  "partition",
+					ObjectName:  tableName,
+					ParentName:  targetTable.PartitionOf,
+					Severity:    Low,
+					Description: fmt.Sprintf("Partition %s of %s was added", tableName, targetTable.PartitionOf),
+					Details: map[string]any{
+						"bound": targetTable.PartitionBound,
+					},
+				})
+				continue
+			}
+
+			diff.AddChange(Change{
+				Type:        Added,
+				ObjectType:  "table",
+				ObjectName:  tableName,
+				ParentName:  "",
+				Severity:    Low,
+				Description: fmt.Sprintf("Table %s was added", tableName),
+				Details: map[string]any{
+					"columns": len(targetTable.Columns),
+				},
+			})
+		}
+	}
+
+	for tableName, srcTable := range sourceTables {
+		targetTable, exists := targetTables[tableName]
+		if !exists {
+			continue
+		}
+
+		comparePartitioning(diff, tableName, srcTable, targetTable)
+		compareTableOptions(diff, tableName, srcTable, targetTable)
+
+		// partitions inherit their columns and constraints, which are compared on the parent
+		if !srcTable.IsPartition() && !targetTable.IsPartition() {
+			compareColumns(diff, tableName, srcTable, targetTable)
+			compareConstraints(diff, tableName, srcTable, targetTable)
+		}
+		compareRowSecurity(diff, tableName, srcTable, targetTable)
+		comparePolicies(diff, tableName, srcTable, targetTable)
+
+		//compare triggers
+		//compare views
+		//compare procedures
+		//compare functions
+		//compare events
+		//compare sequences
+	}
+}
+
+func comparePartitioning(diff *Diff, tableName string, sourceTable, targetTable *models.Table) {
+	modified := func(what string, oldValue, newValue string) {
+		// rows are routed by the partitioning, so any change moves data or rejects inserts
+		diff.AddChange(Change{
+			Type:        Modified,
+			ObjectType:  "table",
+			ObjectName:  tableName,
+			Severity:    High,
+			Description: fmt.Sprintf("Table %s %s changed from %s to %s", tableName, what, oldValue, newValue),
+			Details: map[string]any{
+				"old_" + strings.ReplaceAll(what, " ", "_"): oldValue,
+				"new_" + strings.ReplaceAll(what, " ", "_"): newValue,
+			},
+		})
+	}
+
+	if sourceTable.PartitionStrategy != targetTable.PartitionStrategy || normalizeExpr(sourceTable.PartitionKey) != normalizeExpr(targetTable.PartitionKey) {
+		modified("partitioning", partitioningOrNone(sourceTable), partitioningOrNone(targetTable))
+	}
+
+	if sourceTable.PartitionOf != targetTable.PartitionOf {
+		modified("parent", orNone(sourceTable.PartitionOf), orNone(targetTable.PartitionOf))
+	} else if normalizeExpr(sourceTable.PartitionBound) != normalizeExpr(targetTable.PartitionBound) {
+		modified("partition bound", sourceTable.PartitionBound, targetTable.PartitionBound)
+	}
+}
+
+// compareTableOptions compares MySQL's storage engine, character set and collation. Each is
+// only compared when both sides record it, as parsed DDL usually leaves them to the server
+func compareTableOptions(diff *Diff, tableName string, sourceTable, targetTable *models.Table) {
+	modified := func(what string, oldValue, newValue string) {
+		if oldValue == "" || newValue == "" || strings.EqualFold(oldValue, newValue) {
+			return
+		}
+
+		// each of these rebuilds the table
+		diff.AddChange(Change{
+			Type:        Modified,
+			ObjectType:  "table",
+			ObjectName:  tableName,
+			Severity:    Medium,
+			Description: fmt.Sprintf("Table %s %s changed from %s to %s", tableName, what, oldValue, newValue),
+			Details: map[string]any{
+				"old_" + strings.ReplaceAll(what, " ", "_"): oldValue,
+				"new_" + strings.ReplaceAll(what, " ", "_"): newValue,
+			},
+		})
+	}
+
+	modified("engine", sourceTable.Engine, targetTable.Engine)
+	modified("character set", sourceTable.Charset, targetTable.Charset)
+	modified("collation", sourceTable.Collation, targetTable.Collation)
+}
+
+func partitioningOrNone(t *models.Table) string {
+	if t.PartitionStrategy == "" {
+		return "none"
+	}
+	return fmt.Sprintf("%s (%s)", t.PartitionStrategy, t.PartitionKey)
+}
+
+func compareRowSecurity(diff *Diff, tableName string, sourceTable, targetTable *models.Table) {
+	modified := func(severity SeverityLevel, what string, oldValue, newValue bool) {
+		diff.AddChange(Change{
+			Type:        Modified,
+			ObjectType:  "table",
+			ObjectName:  tableName,
+			Severity:    severity,
+			Description: fmt.Sprintf("Table %s %s changed from %v to %v", tableName, what, oldValue, newValue),
+			Details: map[string]any{
+				"old_" + strings.ReplaceAll(what, " ", "_"): oldValue,
+				"new_" + strings.ReplaceAll(what, " ", "_"): newValue,
+			},
+		})
+	}
+
+	// turning row-level security off exposes every row regardless of the policies
+	if sourceTable.RowSecurity != targetTable.RowSecurity {
+		severity := Medium
+		if !targetTable.RowSecurity {
+			severity = High
+		}
+		modified(severity, "row level security", sourceTable.RowSecurity, targetTable.RowSecurity)
+	}
+
+	if sourceTable.ForceRowSecurity != targetTable.ForceRowSecurity {
+		severity := Medium
+		if !targetTable.ForceRowSecurity {
+			severity = High
+		}
+		modified(severity, "forced row level security", sourceTable.ForceRowSecurity, targetTable.ForceRowSecurity)
+	}
+}
+
+func comparePolicies(diff *Diff, tableName string, sourceTable, targetTable *models.Table) {
+	srcPolicies := make(map[string]*models.Policy)
+	targetPolicies := make(map[string]*models.Policy)
+
+	for _, p := range sourceTable.Policies {
+		srcPolicies[p.Name] = p
+	}
+
+	for _, p := range targetTable.Policies {
+		targetPolicies[p.Name] = p
+	}
+
+	for name, policy := range targetPolicies {
+		if _, exists := srcPolicies[name]; !exists {
+			// a permissive policy grants access, a restrictive one narrows it
+			severity := Medium
+			if policy.Restrictive {
+				severity = Low
+			}
+			diff.AddChange(Change{
+				Type:        Added,
+				ObjectType:  "policy",
+				ObjectName:  name,
+				ParentName:  tableName,
+				Severity:    severity,
+				Description: fmt.Sprintf("Policy %s on %s was added", name, tableName),
+				Details: map[string]any{
+					"command": policy.Command,
+					"roles":   rolesOrPublic(policy.Roles),
+				},
+			})
+		}
+	}
+
+	for name, policy := range srcPolicies {
+		if _, exists := targetPolicies[name]; !exists {
+			diff.AddChange(Change{
+				Type:        Removed,
+				ObjectType:  "policy",
+				ObjectName:  name,
+				ParentName:  tableName,
+				Severity:    High,
+				Description: fmt.Sprintf("Policy %s on %s was removed", name, tableName),
+				Details: map[string]any{
+					"command": policy.Command,
+					"roles":   rolesOrPublic(policy.Roles),
+				},
+			})
+		}
+	}
+
+	for name, srcPolicy := range srcPolicies {
+		tgtPolicy, exists := targetPolicies[name]
+		if !exists {
+			continue
+		}
+
+		modified := func(what string, oldValue, newValue any) {
+			// any change to who a policy applies to or what it allows changes who sees which rows
+			diff.AddChange(Change{
+				Type:        Modified,
+				ObjectType:  "policy",
+				ObjectName:  name,
+				ParentName:  tableName,
+				Severity:    High,
+				Description: fmt.Sprintf("Policy %s on %s %s changed from %v to %v", name, tableName, what, oldValue, newValue),
+				Details: map[string]any{
+					"old_" + strings.ReplaceAll(what, " ", "_"): oldValue,
+					"new_" + strings.ReplaceAll(what, " ", "_"): newValue,
+				},
+			})
+		}
+
+		if srcPolicy.Command != tgtPolicy.Command {
+			modified("command", srcPolicy.Command, tgtPolicy.Command)
+		}
+
+		if srcPolicy.Restrictive != tgtPolicy.Restrictive {
+			modified("type", policyType(srcPolicy), policyType(tgtPolicy))
+		}
+
+		if !sameRoles(srcPolicy.Roles, tgtPolicy.Roles) {
+			modified("roles", rolesOrPublic(srcPolicy.Roles), rolesOrPublic(tgtPolicy.Roles))
+		}
+
+		if normalizeExpr(srcPolicy.Using) != normalizeExpr(tgtPolicy.Using) {
+			modified("using expression", srcPolicy.Using, tgtPolicy.Using)
+		}
+
+		if normalizeExpr(srcPolicy.WithCheck) != normalizeExpr(tgtPolicy.WithCheck) {
+			modified("with check expression", srcPolicy.WithCheck, tgtPolicy.WithCheck)
+		}
+	}
+}
+
+func policyType(policy *models.Policy) string {
+	if policy.Restrictive {
+		return "RESTRICTIVE"
+	}
+	return "PERMISSIVE"
+}
+
+func rolesOrPublic(roles []string) string {
+	if len(roles) == 0 {
+		return "public"
+	}
+	return strings.Join(roles, ", ")
+}
+
+// sameRoles reports whether two role lists name the same roles in any order
+func sameRoles(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+func compareSequences(diff *Diff, src, target *models.Schema) {
+	srcSeqs := make(map[string]*models.Sequence)
+	targetSeqs := make(map[string]*models.Sequence)
+
+	for _, seq := range src.Sequences {
+		srcSeqs[seq.Name] = seq
+	}
+
+	for _, seq := range target.Sequences {
+		targetSeqs[seq.Name] = seq
+	}
+
+	for name, seq := range targetSeqs {
+		if _, exists := srcSeqs[name]; !exists {
+			diff.AddChange(Change{
+				Type:        Added,
+				ObjectType:  "sequence",
+				ObjectName:  name,
+				Severity:    Low,
+				Description: fmt.Sprintf("Sequence %s was added", name),
+				Details: map[string]any{
+					"data_type": seq.DataType,
+					"owned_by":  seq.OwnedBy,
+				},
+			})
+		}
+	}
+
+	for name, seq := range srcSeqs {
+		if _, exists := targetSeqs[name]; !exists {
+			// column defaults calling nextval() on it will fail
+			diff.AddChange(Change{
+				Type:        Removed,
+				ObjectType:  "sequence",
+				ObjectName:  name,
+				Severity:    High,
+				Description: fmt.Sprintf("Sequence %s was removed", name),
+				Details: map[string]any{
+					"data_type": seq.DataType,
+					"owned_by":  seq.OwnedBy,
+				},
+			})
+		}
+	}
+
+	for name, srcSeq := range srcSeqs {
+		tgtSeq, exists := targetSeqs[name]
+		if !exists {
+			continue
+		}
+
+		modified := func(severity SeverityLevel, what string, oldValue, newValue any) {
+			diff.AddChange(Change{
+				Type:        Modified,
+				ObjectType:  "sequence",
+				ObjectName:  name,
+				Severity:    severity,
+				Description: fmt.Sprintf("Sequence %s %s changed from %v to %v", name, what, oldValue, newValue),
+				Details: map[string]any{
+					"old_" + strings.ReplaceAll(what, " ", "_"): oldValue,
+					"new_" + strings.ReplaceAll(what, " ", "_"): newValue,
+				},
+			})
+		}
+
+		if srcSeq.DataType != tgtSeq.DataType {
+			severity := Medium
+			if isNarrowingNumericChange(srcSeq.DataType, tgtSeq.DataType) {
+				severity = High
+			}
+			modified(severity, "data type", srcSeq.DataType, tgtSeq.DataType)
+		}
+
+		if srcSeq.Increment != tgtSeq.Increment {
+			modified(Medium, "increment", srcSeq.Increment, tgtSeq.Increment)
+		}
+
+		if srcSeq.Min != tgtSeq.Min {
+			modified(Medium, "min value", srcSeq.Min, tgtSeq.Min)
+		}
+
+		if srcSeq.Max != tgtSeq.Max {
+			severity := Medium
+			if tgtSeq.Max < srcSeq.Max {
+				// a lower ceiling makes nextval() fail sooner
+				severity = High
+			}
+			modified(severity, "max value", srcSeq.Max, tgtSeq.Max)
+		}
+
+		if srcSeq.Cycle != tgtSeq.Cycle {
+			modified(Medium, "cycle", srcSeq.Cycle, tgtSeq.Cycle)
+		}
+
+		// the start value only matters when the sequence is created or restarted
+		if srcSeq.Start != tgtSeq.Start {
+			modified(Low, "start value", srcSeq.Start, tgtSeq.Start)
+		}
+
+		if srcSeq.Cache != tgtSeq.Cache {
+			modified(Low, "cache", srcSeq.Cache, tgtSeq.Cache)
+		}
+
+		if srcSeq.OwnedBy != tgtSeq.OwnedBy {
+			modified(Low, "owner column", ownerOrNone(srcSeq.OwnedBy), ownerOrNone(tgtSeq.OwnedBy))
+		}
+	}
+}
+
+func ownerOrNone(ownedBy string) string {
+	if ownedBy == "" {
+		return "none"
+	}
+	return ownedBy
+}
+
+func compareFunctions(diff *Diff, src, target *models.Schema) {
+	srcFuncs := make(map[string]*models.Function)
+	targetFuncs := make(map[string]*models.Function)
+
+	// overloads share a name, so functions are matched on their full signature
+	for _, fn := range src.Functions {
+		srcFuncs[fn.Signature()] = fn
+	}
+
+	for _, fn := range target.Functions {
+		targetFuncs[fn.Signature()] = fn
+	}
+
+	for signature, fn := range targetFuncs {
+		if _, exists := srcFuncs[signature]; !exists {
+			diff.AddChange(Change{
+				Type:        Added,
+				ObjectType:  strings.ToLower(kindOrDefault(fn.Kind)),
+				ObjectName:  signature,
+				Severity:    Low,
+				Description: fmt.Sprintf("%s %s was added", kindTitle(fn.Kind), signature),
+				Details: map[string]any{
+					"returns":  fn.ReturnType,
+					"language": fn.Language,
+				},
+			})
+		}
+	}
+
+	for signature, fn := range srcFuncs {
+		if _, exists := targetFuncs[signature]; !exists {
+			diff.AddChange(Change{
+				Type:        Removed,
+				ObjectType:  strings.ToLower(kindOrDefault(fn.Kind)),
+				ObjectName:  signature,
+				Severity:    High,
+				Description: fmt.Sprintf("%s %s was removed", kindTitle(fn.Kind), signature),
+				Details: map[string]any{
+					"returns":  fn.ReturnType,
+					"language": fn.Language,
+				},
+			})
+		}
+	}
+
+	for signature, srcFn := range srcFuncs {
+		tgtFn, exists := targetFuncs[signature]
+		if !exists {
+			continue
+		}
+
+		modified := func(severity SeverityLevel, what string, oldValue, newValue any) {
+			diff.AddChange(Change{
+				Type:        Modified,
+				ObjectType:  strings.ToLower(kindOrDefault(tgtFn.Kind)),
+				ObjectName:  signature,
+				Severity:    severity,
+				Description: fmt.Sprintf("%s %s %s changed from %v to %v", kindTitle(tgtFn.Kind), signature, what, oldValue, newValue),
+				Details: map[string]any{
+					"old_" + strings.ReplaceAll(what, " ", "_"): oldValue,
+					"new_" + strings.ReplaceAll(what, " ", "_"): newValue,
+				},
+			})
+		}
+
+		if kindOrDefault(srcFn.Kind) != kindOrDefault(tgtFn.Kind) {
+			modified(High, "kind", kindOrDefault(srcFn.Kind), kindOrDefault(tgtFn.Kind))
+		}
+
+		if normalizeExpr(srcFn.ReturnType) != normalizeExpr(tgtFn.ReturnType) {
+			modified(High, "return type", srcFn.ReturnType, tgtFn.ReturnType)
+		}
+
+		if !strings.EqualFold(srcFn.Language, tgtFn.Language) {
+			modified(Medium, "language", srcFn.Language, tgtFn.Language)
+		}
+
+		if srcFn.SecurityDefiner != tgtFn.SecurityDefiner {
+			// SECURITY DEFINER functions run with their owner's privileges
+			modified(High, "security definer", srcFn.SecurityDefiner, tgtFn.SecurityDefiner)
+		}
+
+		if srcFn.Volatility != tgtFn.Volatility {
+			modified(Medium, "volatility", srcFn.Volatility, tgtFn.Volatility)
+		}
+
+		if srcFn.Strict != tgtFn.Strict {
+			modified(Medium, "strictness", srcFn.Strict, tgtFn.Strict)
+		}
+
+		if srcFn.Parallel != tgtFn.Parallel {
+			modified(Low, "parallel safety", srcFn.Parallel, tgtFn.Parallel)
+		}
+
+		if normalizeBody(srcFn.Body) != normalizeBody(tgtFn.Body) {
+			diff.AddChange(Change{
+				Type:        Modified,
+				ObjectType:  strings.ToLower(kindOrDefault(tgtFn.Kind)),
+				ObjectName:  signature,
+				Severity:    Medium,
+				Description: fmt.Sprintf("%s %s body changed", kindTitle(tgtFn.Kind), signature),
+				Details: map[string]any{
+					"old_body": srcFn.Body,
+					"new_body": tgtFn.Body,
+				},
+			})
+		}
+	}
+}
+
+func kindOrDefault(kind string) string {
+	if kind == "" {
+		return "FUNCTION"
+	}
+	return kind
+}
+
+func kindTitle(kind string) string {
+	kind = strings.ToLower(kindOrDefault(kind))
+	return strings.ToUpper(kind[:1]) + kind[1:]
+}
+
+var (
+	blockCommentRegex = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	lineCommentRegex  = regexp.MustCompile(`--[^\n]*`)
+)
+
+// normalizeBody strips comments and formatting from a function body so that only code changes are reported
+func normalizeBody(body string) string {
+	body = blockCommentRegex.ReplaceAllString(body, " ")
+	body = lineCommentRegex.ReplaceAllString(body, " ")
+	return strings.Join(strings.Fields(body), " ")
+}
+
+// privilegeKey identifies a privilege by everything but its grant option
+func privilegeKey(p *models.Privilege) string {
+	return fmt.Sprintf("%s on %s %s to %s", p.Privilege, strings.ToLower(p.ObjectType), p.Object, p.Grantee)
+}
+
+// revokedKey identifies a revoked default privilege, keeping it apart from a grant of the same privilege
+func revokedKey(p *models.Privilege) string {
+	return "revoked " + privilegeKey(p)
+}
+
+func comparePrivileges(diff *Diff, src, target *models.Schema) {
+	srcPrivs := make(map[string]*models.Privilege)
+	targetPrivs := make(map[string]*models.Privilege)
+
+	srcRevoked := make(map[string]*models.Privilege)
+	targetRevoked := make(map[string]*models.Privilege)
+
+	for _, p := range src.Privileges {
+		if p.Revoked {
+			srcRevoked[revokedKey(p)] = p
+		} else {
+			srcPrivs[privilegeKey(p)] = p
+		}
+	}
+
+	for _, p := range target.Privileges {
+		if p.Revoked {
+			targetRevoked[revokedKey(p)] = p
+		} else {
+			targetPrivs[privilegeKey(p)] = p
+		}
+	}
+
+	for key, priv := range targetRevoked {
+		if _, exists := srcRevoked[key]; !exists {
+			// the grantee lost a privilege the reference leaves it by default
+			diff.AddChange(Change{
+				Type:        Added,
+				ObjectType:  "privilege",
+				ObjectName:  key,
+				ParentName:  priv.Object,
+				Severity:    High,
+				Description: fmt.Sprintf("Default privilege %s was revoked", privilegeKey(priv)),
+			})
+		}
+	}
+
+	for key, priv := range srcRevoked {
+		if _, exists := targetRevoked[key]; !exists {
+			// restoring a default widens access, to everyone when it's PUBLIC's
+			severity := Medium
+			if priv.Grantee == "public" {
+				severity = High
+			}
+			diff.AddChange(Change{
+				Type:        Removed,
+				ObjectType:  "privilege",
+				ObjectName:  key,
+				ParentName:  priv.Object,
+				Severity:    severity,
+				Description: fmt.Sprintf("Default privilege %s was restored", privilegeKey(priv)),
+			})
+		}
+	}
+
+	for key, priv := range targetPrivs {
+		if _, exists := srcPrivs[key]; !exists {
+			// a hand-granted privilege widens access, and one granted to PUBLIC widens it to everyone
+			severity := Medium
+			if priv.Grantee == "public" {
+				severity = High
+			}
+			diff.AddChange(Change{
+				Type:        Added,
+				ObjectType:  "privilege",
+				ObjectName:  key,
+				ParentName:  priv.Object,
+				Severity:    severity,
+				Description: fmt.Sprintf("Privilege %s was granted", key),
+				Details: map[string]any{
+					"grantable": priv.Grantable,
+				},
+			})
+		}
+	}
+
+	for key, priv := range srcPrivs {
+		if _, exists := targetPrivs[key]; !exists {
+			// the grantee can no longer do what the reference allows
+			diff.AddChange(Change{
+				Type:        Removed,
+				ObjectType:  "privilege",
+				ObjectName:  key,
+				ParentName:  priv.Object,
+				Severity:    High,
+				Description: fmt.Sprintf("Privilege %s was revoked", key),
+				Details: map[string]any{
+					"grantable": priv.Grantable,
+				},
+			})
+		}
+	}
+
+	for key, srcPriv := range srcPrivs {
+		tgtPriv, exists := targetPrivs[key]
+		if !exists || srcPriv.Grantable == tgtPriv.Grantable {
+			continue
+		}
+
+		diff.AddChange(Change{
+			Type:        Modified,
+			ObjectType:  "privilege",
+			ObjectName:  key,
+			ParentName:  srcPriv.Object,
+			Severity:    Medium,
+			Description: fmt.Sprintf("Privilege %s grant option changed from %v to %v", key, srcPriv.Grantable, tgtPriv.Grantable),
+			Details: map[string]any{
+				"old_grantable": srcPriv.Grantable,
+				"new_grantable": tgtPriv.Grantable,
+			},
+		})
+	}
+}
+
+func compareOwners(diff *Diff, src, target *models.Schema) {
+	targetOwners := make(map[string]*models.Owner)
+	for _, o := range target.Owners {
+		targetOwners[o.ObjectType+" "+o.Object] = o
+	}
+
+	// schema files rarely set every owner, so only owners recorded on both sides are compared
+	for _, srcOwner := range src.Owners {
+		tgtOwner, exists := targetOwners[srcOwner.ObjectType+" "+srcOwner.Object]
+		if !exists || srcOwner.Owner == tgtOwner.Owner {
+			continue
+		}
+
+		// the owner bypasses privileges and, without FORCE ROW LEVEL SECURITY, policies too
+		diff.AddChange(Change{
+			Type:        Modified,
+			ObjectType:  "owner",
+			ObjectName:  fmt.Sprintf("%s %s", strings.ToLower(srcOwner.ObjectType), srcOwner.Object),
+			Severity:    Medium,
+			Description: fmt.Sprintf("Owner of %s %s changed from %s to %s", strings.ToLower(srcOwner.ObjectType), srcOwner.Object, srcOwner.Owner, tgtOwner.Owner),
+			Details: map[string]any{
+				"old_owner": srcOwner.Owner,
+				"new_owner": tgtOwner.Owner,
+			},
+		})
+	}
+}
+
+func compareExtensions(diff *Diff, src, target *models.Schema) {
+	srcExts := make(map[string]*models.Extension)
+	targetExts := make(map[string]*models.Extension)
+
+	for _, ext := range src.Extensions {
+		srcExts[ext.Name] = ext
+	}
+
+	for _, ext := range target.Extensions {
+		targetExts[ext.Name] = ext
+	}
+
+	for name, ext := range targetExts {
+		if _, exists := srcExts[name]; !exists {
+			diff.AddChange(Change{
+				Type:        Added,
+				ObjectType:  "extension",
+				ObjectName:  name,
+				Severity:    Low,
+				Description: fmt.Sprintf("Extension %s was added", name),
+				Details: map[string]any{
+					"version": ext.Version,
+				},
+			})
+		}
+	}
+
+	for name, ext := range srcExts {
+		if _, exists := targetExts[name]; !exists {
+			// the types and functions it provides are gone with it
+			diff.AddChange(Change{
+				Type:        Removed,
+				ObjectType:  "extension",
+				ObjectName:  name,
+				Severity:    High,
+				Description: fmt.Sprintf("Extension %s was removed", name),
+				Details: map[string]any{
+					"version": ext.Version,
+				},
+			})
+		}
+	}
+
+	for name, srcExt := range srcExts {
+		tgtExt, exists := targetExts[name]
+		if !exists {
+			continue
+		}
+
+		modified := func(severity SeverityLevel, what string, oldValue, newValue any) {
+			diff.AddChange(Change{
+				Type:        Modified,
+				ObjectType:  "extension",
+				ObjectName:  name,
+				Severity:    severity,
+				Description: fmt.Sprintf("Extension %s %s changed from %v to %v", name, what, oldValue, newValue),
+				Details: map[string]any{
+					"old_" + strings.ReplaceAll(what, " ", "_"): oldValue,
+					"new_" + strings.ReplaceAll(what, " ", "_"): newValue,
+				},
+			})
+		}
+
+		// schema files often leave the version and schema to the server's defaults, so
+		// only pinned values are compared
+		if srcExt.Version != "" && tgtExt.Version != "" && srcExt.Version != tgtExt.Version {
+			modified(Medium, "version", srcExt.Version, tgtExt.Version)
+		}
+
+		if srcExt.Schema != "" && tgtExt.Schema != "" && srcExt.Schema != tgtExt.Schema {
+			modified(Medium, "schema", srcExt.Schema, tgtExt.Schema)
+		}
+	}
+}
+
+func compareEnums(diff *Diff, src, target *models.Schema) {
+	srcEnums := make(map[string]*models.Enum)
+	targetEnums := make(map[string]*models.Enum)
+
+	for _, enum := range src.Enums {
+		srcEnums[enum.Name] = enum
+	}
+
+	for _, enum := range target.Enums {
+		targetEnums[enum.Name] = enum
+	}
+
+	for name, enum := range targetEnums {
+		if _, exists := srcEnums[name]; !exists {
+			diff.AddChange(Change{
+				Type:        Added,
+				ObjectType:  "enum",
+				ObjectName:  name,
+				Severity:    Low,
+				Description: fmt.Sprintf("Enum %s was added", name),
+				Details: map[string]any{
+					"values": enum.Labels,
+				},
+			})
+		}
+	}
+
+	for name, enum := range srcEnums {
+		if _, exists := targetEnums[name]; !exists {
+			diff.AddChange(Change{
+				Type:        Removed,
+				ObjectType:  "enum",
+				ObjectName:  name,
+				Severity:    High,
+				Description: fmt.Sprintf("Enum %s was removed", name),
+				Details: map[string]any{
+					"values": enum.Labels,
+				},
+			})
+		}
+	}
+
+	for name, srcEnum := range srcEnums {
+		tgtEnum, exists := targetEnums[name]
+		if !exists {
+			continue
+		}
+
+		// new values can be added in place, but values can't be dropped from an enum
+		// still in use, and reordering them changes how existing rows sort and compare
+		for _, label := range tgtEnum.Labels {
+			if !slices.Contains(srcEnum.Labels, label) {
+				diff.AddChange(Change{
+					Type:        Modified,
+					ObjectType:  "enum",
+					ObjectName:  name,
+					Severity:    Low,
+					Description: fmt.Sprintf("Enum %s value '%s' was added", name, label),
+					Details: map[string]any{
+						"added_value": label,
+					},
+				})
+			}
+		}
+
+		for _, label := range srcEnum.Labels {
+			if !slices.Contains(tgtEnum.Labels, label) {
+				diff.AddChange(Change{
+					Type:        Modified,
+					ObjectType:  "enum",
+					ObjectName:  name,
+					Severity:    High,
+					Description: fmt.Sprintf("Enum %s value '%s' was removed", name, label),
+					Details: map[string]any{
+						"removed_value": label,
+					},
+				})
+			}
+		}
+
+		srcOrder := commonLabels(srcEnum.Labels, tgtEnum.Labels)
+		tgtOrder := commonLabels(tgtEnum.Labels, srcEnum.Labels)
+		if !slices.Equal(srcOrder, tgtOrder) {
+			diff.AddChange(Change{
+				Type:        Modified,
+				ObjectType:  "enum",
+				ObjectName:  name,
+				Severity:    High,
+				Description: fmt.Sprintf("Enum %s values were reordered", name),
+				Details: map[string]any{
+					"old_order": srcOrder,
+					"new_order": tgtOrder,
+				},
+			})
+		}
+	}
+}
+
+// commonLabels returns the labels of a that also appear in b, in the order of a
+func commonLabels(a, b []string) []string {
+	common := make([]string, 0, len(a))
+	for _, label := range a {
+		if slices.Contains(b, label) {
+			common = append(common, label)
+		}
+	}
+	return common
+}
+
+func compareDomains(diff *Diff, src, target *models.Schema) {
+	srcDomains := make(map[string]*models.Domain)
+	targetDomains := make(map[string]*models.Domain)
+
+	for _, domain := range src.Domains {
+		srcDomains[domain.Name] = domain
+	}
+
+	for _, domain := range target.Domains {
+		targetDomains[domain.Name] = domain
+	}
+
+	for name, domain := range targetDomains {
+		if _, exists := srcDomains[name]; !exists {
+			diff.AddChange(Change{
+				Type:        Added,
+				ObjectType:  "domain",
+				ObjectName:  name,
+				Severity:    Low,
+				Description: fmt.Sprintf("Domain %s was added", name),
+				Details: map[string]any{
+					"base_type": domain.BaseType,
+				},
+			})
+		}
+	}
+
+	for name, domain := range srcDomains {
+		if _, exists := targetDomains[name]; !exists {
+			diff.AddChange(Change{
+				Type:        Removed,
+				ObjectType:  "domain",
+				ObjectName:  name,
+				Severity:    High,
+				Description: fmt.Sprintf("Domain %s was removed", name),
+				Details: map[string]any{
+					"base_type": domain.BaseType,
+				},
+			})
+		}
+	}
+
+	for name, srcDomain := range srcDomains {
+		tgtDomain, exists := targetDomains[name]
+		if !exists {
+			continue
+		}
+
+		modified := func(severity SeverityLevel, what string, oldValue, newValue any) {
+			diff.AddChange(Change{
+				Type:        Modified,
+				ObjectType:  "domain",
+				ObjectName:  name,
+				Severity:    severity,
+				Description: fmt.Sprintf("Domain %s %s changed from %v to %v", name, what, oldValue, newValue),
+				Details: map[string]any{
+					"old_" + strings.ReplaceAll(what, " ", "_"): oldValue,
+					"new_" + strings.ReplaceAll(what, " ", "_"): newValue,
+				},
+			})
+		}
+
+		if srcDomain.BaseType != tgtDomain.BaseType {
+			modified(High, "base type", srcDomain.BaseType, tgtDomain.BaseType)
+		}
+
+		if normalizeExpr(srcDomain.DefaultValue) != normalizeExpr(tgtDomain.DefaultValue) {
+			modified(Medium, "default", orNone(srcDomain.DefaultValue), orNone(tgtDomain.DefaultValue))
+		}
+
+		if srcDomain.NotNull != tgtDomain.NotNull {
+			severity := Low
+			if tgtDomain.NotNull {
+				// every column of the domain has to be free of NULLs
+				severity = High
+			}
+			modified(severity, "not null", srcDomain.NotNull, tgtDomain.NotNull)
+		}
+
+		// checks are matched on their expression, as unnamed ones get generated names
+		srcChecks := make(map[string]*models.Constraint)
+		tgtChecks := make(map[string]*models.Constraint)
+		for _, con := range srcDomain.Constraints {
+			srcChecks[normalizeExpr(con.CheckExpr)] = con
+		}
+		for _, con := range tgtDomain.Constraints {
+			tgtChecks[normalizeExpr(con.CheckExpr)] = con
+		}
+
+		for expr, con := range tgtChecks {
+			if _, exists := srcChecks[expr]; !exists {
+				diff.AddChange(Change{
+					Type:        Modified,
+					ObjectType:  "domain",
+					ObjectName:  name,
+					Severity:    Medium,
+					Description: fmt.Sprintf("Domain %s check %s was added", name, con.Name),
+					Details: map[string]any{
+						"check": con.CheckExpr,
+					},
+				})
+			}
+		}
+
+		for expr, con := range srcChecks {
+			if _, exists := tgtChecks[expr]; !exists {
+				diff.AddChange(Change{
+					Type:        Modified,
+					ObjectType:  "domain",
+					ObjectName:  name,
+					Severity:    Low,
+					Description: fmt.Sprintf("Domain %s check %s was removed", name, con.Name),
+					Details: map[string]any{
+						"check": con.CheckExpr,
+					},
+				})
+			}
+		}
+	}
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+func compareCompositeTypes(diff *Diff, src, target *models.Schema) {
+	srcTypes := make(map[string]*models.CompositeType)
+	targetTypes := make(map[string]*models.CompositeType)
+
+	for _, ct := range src.CompositeTypes {
+		srcTypes[ct.Name] = ct
+	}
+
+	for _, ct := range target.CompositeTypes {
+		targetTypes[ct.Name] = ct
+	}
+
+	for name, ct := range targetTypes {
+		if _, exists := srcTypes[name]; !exists {
+			diff.AddChange(Change{
+				Type:        Added,
+				ObjectType:  "type",
+				ObjectName:  name,
+				Severity:    Low,
+				Description: fmt.Sprintf("Composite type %s was added", name),
+				Details: map[string]any{
+					"attributes": len(ct.Attributes),
+				},
+			})
+		}
+	}
+
+	for name, ct := range srcTypes {
+		if _, exists := targetTypes[name]; !exists {
+			diff.AddChange(Change{
+				Type:        Removed,
+				ObjectType:  "type",
+				ObjectName:  name,
+				Severity:    High,
+				Description: fmt.Sprintf("Composite type %s was removed", name),
+				Details: map[string]any{
+					"attributes": len(ct.Attributes),
+				},
+			})
+		}
+	}
+
+	for name, srcType := range srcTypes {
+		tgtType, exists := targetTypes[name]
+		if !exists {
+			continue
+		}
+
+		srcAttrs := make(map[string]*models.Column)
+		tgtAttrs := make(map[string]*models.Column)
+		for _, attr := range srcType.Attributes {
+			srcAttrs[attr.Name] = attr
+		}
+		for _, attr := range tgtType.Attributes {
+			tgtAttrs[attr.Name] = attr
+		}
+
+		for attrName, attr := range tgtAttrs {
+			if _, exists := srcAttrs[attrName]; !exists {
+				diff.AddChange(Change{
+					Type:        Added,
+					ObjectType:  "attribute",
+					ObjectName:  attrName,
+					ParentName:  name,
+					Severity:    Medium,
+					Description: fmt.Sprintf("Attribute %s.%s was added", name, attrName),
+					Details: map[string]any{
+						"data_type": attr.DataType,
+					},
+				})
+			}
+		}
+
+		for attrName, srcAttr := range srcAttrs {
+			tgtAttr, exists := tgtAttrs[attrName]
+			if !exists {
+				diff.AddChange(Change{
+					Type:        Removed,
+					ObjectType:  "attribute",
+					ObjectName:  attrName,
+					ParentName:  name,
+					Severity:    High,
+					Description: fmt.Sprintf("Attribute %s.%s was removed", name, attrName),
+					Details: map[string]any{
+						"data_type": srcAttr.DataType,
+					},
+				})
+				continue
+			}
+
+			if srcAttr.DataType != tgtAttr.DataType {
+				diff.AddChange(Change{
+					Type:        Modified,
+					ObjectType:  "attribute",
+					ObjectName:  attrName,
+					ParentName:  name,
+					Severity:    High,
+					Description: fmt.Sprintf("Attribute %s.%s data type changed from %s to %s", name, attrName, srcAttr.DataType, tgtAttr.DataType),
+					Details: map[string]any{
+						"old_data_type": srcAttr.DataType,
+						"new_data_type": tgtAttr.DataType,
+					},
+				})
+			}
+		}
 	}
 }
 
 func BuildDiff(src, target *models.Schema) *Diff {
 	diff := NewDiff()
 	compareTables(diff, src, target)
+	compareIndexes(diff, src, target)
+	compareTriggers(diff, src, target)
+	compareSequences(diff, src, target)
+	compareFunctions(diff, src, target)
+	compareExtensions(diff, src, target)
+	compareEnums(diff, src, target)
+	compareDomains(diff, src, target)
+	compareCompositeTypes(diff, src, target)
+	comparePrivileges(diff, src, target)
+	compareOwners(diff, src, target)
 	return diff
 }
@@ -0,0 +1,36 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/Richd0tcom/schedrift/internal/models"
+)
+
+func TestComparePrivilegesRevokedDefault(t *testing.T) {
+	revoked := &models.Privilege{ObjectType: "FUNCTION", Object: "add(integer, integer)", Grantee: "public", Privilege: "EXECUTE", Revoked: true}
+	reference := &models.Schema{Name: "app", Privileges: []*models.Privilege{revoked}}
+	live := &models.Schema{Name: "app"}
+
+	// the live database still lets PUBLIC execute the function
+	diff := NewDiff()
+	comparePrivileges(diff, reference, live)
+	if len(diff.Changes) != 1 || diff.Changes[0].Type != Removed || diff.Changes[0].Severity != High {
+		t.Fatalf("expected the restored default to be reported, got %+v", diff.Changes)
+	}
+
+	// and the other way around, the live database revoked it
+	diff = NewDiff()
+	comparePrivileges(diff, live, reference)
+	if len(diff.Changes) != 1 || diff.Changes[0].Type != Added || diff.Changes[0].Severity != High {
+		t.Fatalf("expected the revoked default to be reported, got %+v", diff.Changes)
+	}
+
+	// a revoked default isn't the same as a grant of the privilege
+	granted := *revoked
+	granted.Revoked = false
+	diff = NewDiff()
+	comparePrivileges(diff, reference, &models.Schema{Name: "app", Privileges: []*models.Privilege{&granted}})
+	if len(diff.Changes) != 2 {
+		t.Fatalf("expected the revoke and the grant to both be reported, got %+v", diff.Changes)
+	}
+}
@@ -2,6 +2,10 @@ package models
 
 import (
 	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -17,13 +21,19 @@ const (
 )
 
 type Schema struct {
-	Name      string
-	Tables    []*Table
-	Views     []*View
-	Triggers  []*Trigger
-	Indexes   []*Index
-	Functions []*Function
-	Sequences []*Sequence
+	Name           string
+	Tables         []*Table
+	Views          []*View
+	Triggers       []*Trigger
+	Indexes        []*Index
+	Functions      []*Function
+	Sequences      []*Sequence
+	Enums          []*Enum
+	Domains        []*Domain
+	CompositeTypes []*CompositeType
+	Extensions     []*Extension
+	Privileges     []*Privilege
+	Owners         []*Owner
 }
 
 type Table struct {
@@ -32,6 +42,35 @@ type Table struct {
 	Columns     []*Column
 	Constraints []*Constraint
 	Comment     string
+
+	RowSecurity      bool // ENABLE ROW LEVEL SECURITY
+	ForceRowSecurity bool // FORCE ROW LEVEL SECURITY, which applies the policies to the owner too
+	Policies         []*Policy
+
+	PartitionStrategy string // RANGE, LIST or HASH when the table is partitioned
+	PartitionKey      string // e.g. "created_at" or "lower(name)"
+	PartitionOf       string // the parent of a partition, whose columns and constraints it inherits
+	PartitionBound    string // e.g. "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')" or "DEFAULT"
+
+	// MySQL only
+	Engine    string // storage engine, e.g. InnoDB
+	Charset   string // default character set of the table's columns
+	Collation string // default collation of the table's columns
+}
+
+// IsPartition reports whether the table is a partition of another table
+func (t *Table) IsPartition() bool {
+	return t.PartitionOf != ""
+}
+
+type Policy struct {
+	Name        string
+	Table       string
+	Command     string   // ALL, SELECT, INSERT, UPDATE or DELETE
+	Restrictive bool     // policies are PERMISSIVE unless created AS RESTRICTIVE
+	Roles       []string // empty means PUBLIC
+	Using       string
+	WithCheck   string
 }
 
 type Column struct {
@@ -39,61 +78,178 @@ type Column struct {
 	DataType     string
 	IsNullable   bool
 	DefaultValue string
+	Identity     string // ALWAYS or BY DEFAULT for identity columns
 	Comment      string
+
+	// derived from DataType by SetDataType, 0 when the type doesn't declare them
+	Length    int // character and bit types
+	Precision int // numeric and time types
+	Scale     int // numeric types
+	ArrayDims int
+
+	Collation string // only set when it isn't the type's default
+	Generated string // expression of a GENERATED ALWAYS AS (...) column
+	Virtual   bool   // the generated column is computed when read rather than STORED
 }
 
 type Constraint struct {
-	Name       string
-	Type       ConstraintType // PRIMARY KEY, FOREIGN KEY, CHECK, UNIQUE
-	Columns    []string
-	References string // Used for FOREIGN KEY: e.g., "other_table(col1, col2)"
-	CheckExpr  string // Used for CHECK: e.g., "price > 0"
-	RawSQL     string // Optional: if set, returned as-is
+	Name              string
+	Type              ConstraintType // PRIMARY KEY, FOREIGN KEY, CHECK, UNIQUE, EXCLUDE
+	Columns           []string
+	References        string // Used for FOREIGN KEY: e.g., "other_table(col1, col2)"
+	OnDelete          string // Used for FOREIGN KEY: e.g., "CASCADE". Empty means NO ACTION
+	OnUpdate          string // Used for FOREIGN KEY: e.g., "SET NULL". Empty means NO ACTION
+	Deferrable        bool
+	InitiallyDeferred bool
+	CheckExpr         string // Used for CHECK: e.g., "price > 0"
+	Definition        string // Used for EXCLUDE: e.g., "EXCLUDE USING gist (room WITH =, during WITH &&)"
+	RawSQL            string // Optional: if set, returned as-is
 }
 
 type View struct {
-	Schema     string
-	Name       string
-	Definition string
-	Comment    string
+	Schema       string
+	Name         string
+	Definition   string
+	Comment      string
+	Materialized bool
+	WithData     bool     // Used for materialized views: false when created WITH NO DATA
+	Indexes      []*Index // Used for materialized views
 }
 
 type Trigger struct {
 	Name      string
 	Schema    string
 	Table     string
-	Events    []string
-	Timing    string // BEFORE, AFTER, INSTEAD OF
-	Statement string
+	Events    []string // INSERT, DELETE, TRUNCATE, UPDATE or UPDATE OF col1, col2
+	Timing    string   // BEFORE, AFTER, INSTEAD OF
+	ForEach   string   // ROW, STATEMENT
+	When      string   // Optional WHEN condition
+	State     string   // ENABLED, DISABLED, REPLICA, ALWAYS
+	Statement string   // The called function: e.g., "audit.log_change('users')"
 }
 
 type Index struct {
 	Name       string
 	Schema     string
 	Table      string
-	Columns    []string
+	Columns    []string // Key columns, or the expression for expression keys: e.g., "lower(email)"
+	Include    []string // Non-key columns of a covering index
 	IsUnique   bool
-	Method     string // btree, hash, etc.
+	IsValid    bool     // false when a CREATE INDEX CONCURRENTLY failed part way
+	Method     string   // btree, hash, etc.
+	OpClasses  []string // Per key column, empty for the default operator class
+	SortOrders []string // Per key column, empty for the default ASC NULLS LAST: e.g., "DESC"
+	Where      string   // Predicate of a partial index
 	Definition string
 }
 
-type Function struct {
+// Privilege is a single privilege granted on an object of the schema, or on the schema itself.
+// Privileges every role has by default, like EXECUTE on functions, aren't listed
+type Privilege struct {
+	ObjectType string // TABLE (which covers views), SEQUENCE, FUNCTION, PROCEDURE or SCHEMA
+	Object     string // routines are identified by name and argument types, e.g. add(integer, integer)
+	Grantee    string // "public" for PUBLIC
+	Privilege  string // SELECT, INSERT, USAGE, EXECUTE, ...
+	Grantable  bool   // WITH GRANT OPTION
+	Revoked    bool   // a privilege the grantee holds by default, such as PUBLIC's EXECUTE, was revoked
+}
+
+// Owner records the role owning an object of the schema, or the schema itself
+type Owner struct {
+	ObjectType string // as in Privilege
+	Object     string
+	Owner      string
+}
+
+type Extension struct {
+	Name    string
+	Schema  string // the schema the extension's objects are installed into
+	Version string
+}
+
+type Enum struct {
+	Name   string
+	Schema string
+	Labels []string // in sort order
+}
+
+type Domain struct {
+	Name         string
+	Schema       string
+	BaseType     string
+	NotNull      bool
+	DefaultValue string
+	Constraints  []*Constraint // CHECK constraints, whose CheckExpr refers to VALUE
+}
+
+type CompositeType struct {
 	Name       string
 	Schema     string
-	Arguments  string //?
-	ReturnType string
-	Definition string
-	Language   string
+	Attributes []*Column // only Name and DataType are used
+}
+
+type Function struct {
+	Name            string
+	Schema          string
+	Kind            string // FUNCTION or PROCEDURE
+	Arguments       string // identity arguments without defaults: e.g., "a integer, b text"
+	ReturnType      string // empty for procedures
+	Definition      string // complete CREATE statement, when known
+	Body            string
+	Language        string
+	Volatility      string // IMMUTABLE, STABLE or VOLATILE
+	SecurityDefiner bool
+	Strict          bool
+	Parallel        string // SAFE, RESTRICTED or UNSAFE
+}
+
+// Signature identifies a function among its overloads
+func (fn *Function) Signature() string {
+	return fmt.Sprintf("%s(%s)", fn.Name, fn.Arguments)
 }
 
 type Sequence struct {
 	Name      string
 	Schema    string
+	DataType  string // smallint, integer or bigint
 	Start     int64
 	Increment int64
 	Min       int64
 	Max       int64
 	Cache     int64
+	Cycle     bool
+	OwnedBy   string // Column the sequence belongs to: e.g., "users.id"
+}
+
+// NewSequence returns an ascending sequence with PostgreSQL's defaults for dataType
+func NewSequence(schema, name, dataType string) *Sequence {
+	seq := &Sequence{
+		Name:      name,
+		Schema:    schema,
+		DataType:  "bigint",
+		Start:     1,
+		Increment: 1,
+		Min:       1,
+		Max:       math.MaxInt64,
+		Cache:     1,
+	}
+	seq.SetDataType(dataType)
+	return seq
+}
+
+// SetDataType changes the sequence's type, keeping its bounds at that type's defaults
+func (seq *Sequence) SetDataType(dataType string) {
+	switch strings.ToLower(dataType) {
+	case "smallint", "int2":
+		seq.DataType = "smallint"
+		seq.Max = math.MaxInt16
+	case "integer", "int", "int4":
+		seq.DataType = "integer"
+		seq.Max = math.MaxInt32
+	default:
+		seq.DataType = "bigint"
+		seq.Max = math.MaxInt64
+	}
 }
 
 // DatabaseSchema represents the complete schema of a database
@@ -102,6 +258,65 @@ type DatabaseSchema struct {
 	Schemas []*Schema
 }
 
+// ProgressStage identifies what a ProgressEvent reports
+type ProgressStage string
+
+const (
+	ExtractionStarted  ProgressStage = "extraction started"
+	SchemaStarted      ProgressStage = "schema started"
+	ObjectsExtracted   ProgressStage = "objects extracted"
+	SchemaFinished     ProgressStage = "schema finished"
+	ExtractionFinished ProgressStage = "extraction finished"
+)
+
+// ProgressEvent reports how far an extraction has got. Progress is counted in tables, which
+// are listed before the extraction starts
+type ProgressEvent struct {
+	Stage      ProgressStage
+	Schema     string
+	ObjectType string         // set for ObjectsExtracted
+	Count      int            // objects of ObjectType found
+	Counts     map[string]int // objects found so far by type, set for SchemaFinished and ExtractionFinished
+	TablesDone int            // tables extracted so far
+	Tables     int            // tables in the whole extraction
+	Elapsed    time.Duration
+}
+
+// ProgressFunc receives progress events. Calls are never concurrent
+type ProgressFunc func(ProgressEvent)
+
+// String describes the event in a single line
+func (ev ProgressEvent) String() string {
+	switch ev.Stage {
+	case ExtractionStarted:
+		return fmt.Sprintf("extracting %d table(s)", ev.Tables)
+	case SchemaStarted:
+		return fmt.Sprintf("schema %s started", ev.Schema)
+	case ObjectsExtracted:
+		return fmt.Sprintf("schema %s: %d %s (%d of %d tables)", ev.Schema, ev.Count, ev.ObjectType, ev.TablesDone, ev.Tables)
+	case SchemaFinished:
+		return fmt.Sprintf("schema %s finished: %s", ev.Schema, formatCounts(ev.Counts))
+	case ExtractionFinished:
+		return fmt.Sprintf("extraction finished in %s: %s", ev.Elapsed.Round(time.Millisecond), formatCounts(ev.Counts))
+	default:
+		return string(ev.Stage)
+	}
+}
+
+func formatCounts(counts map[string]int) string {
+	types := make([]string, 0, len(counts))
+	for objectType := range counts {
+		types = append(types, objectType)
+	}
+	sort.Strings(types)
+
+	parts := make([]string, 0, len(types))
+	for _, objectType := range types {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[objectType], objectType))
+	}
+	return strings.Join(parts, ", ")
+}
+
 func (ds *DatabaseSchema) ToSQL() string {
 	var sb strings.Builder
 
@@ -124,6 +339,32 @@ func (sc *Schema) ToSQL() string {
 		sb.WriteString(fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s;\n\n", sc.Name))
 	}
 
+	// extensions provide types and functions that everything else may use
+	if len(sc.Extensions) > 0 {
+		sb.WriteString(fmt.Sprintf("-- Extensions: %s\n", sc.Name))
+		for _, ext := range sc.Extensions {
+			sb.WriteString(ext.ToSQL())
+		}
+
+		sb.WriteString("\n")
+	}
+
+	// types come first as columns, domains and other types can depend on them
+	if len(sc.Enums) > 0 || len(sc.Domains) > 0 || len(sc.CompositeTypes) > 0 {
+		sb.WriteString(fmt.Sprintf("-- Types: %s\n", sc.Name))
+		for _, enum := range sc.Enums {
+			sb.WriteString(enum.ToSQL())
+		}
+		for _, domain := range sc.Domains {
+			sb.WriteString(domain.ToSQL())
+		}
+		for _, composite := range sc.CompositeTypes {
+			sb.WriteString(composite.ToSQL())
+		}
+
+		sb.WriteString("\n")
+	}
+
 	if len(sc.Sequences) > 0 {
 		sb.WriteString(fmt.Sprintf("-- Sequences: %s\n", sc.Name))
 		for _, seq := range sc.Sequences {
@@ -136,7 +377,7 @@ func (sc *Schema) ToSQL() string {
 
 	if len(sc.Tables) > 0 {
 		sb.WriteString(fmt.Sprintf("-- Tables: %s\n", sc.Name))
-		for _, table := range sc.Tables {
+		for _, table := range partitionOrder(sc.Tables) {
 			sb.WriteString(table.ToSQL())
 			sb.WriteString("\n")
 		}
@@ -165,6 +406,9 @@ func (sc *Schema) ToSQL() string {
 	if len(sc.Indexes) > 0 {
 		sb.WriteString(fmt.Sprintf("-- Indexes: %s\n", sc.Name))
 		for _, index := range sc.Indexes {
+			if !index.IsValid {
+				sb.WriteString("-- INVALID: index build did not complete\n")
+			}
 			sb.WriteString(index.ToSQL())
 			sb.WriteString(";\n")
 		}
@@ -181,14 +425,21 @@ func (sc *Schema) ToSQL() string {
 		sb.WriteString("\n")
 	}
 
-	if len(sc.Sequences) > 0 {
-		sb.WriteString(fmt.Sprintf("-- Sequences: %s\n", sc.Name))
-		for _, seq := range sc.Sequences {
-			sb.WriteString(seq.ToSQL())
-			sb.WriteString("\n")
+	// ownership can only be set once the owning tables exist
+	for _, seq := range sc.Sequences {
+		if seq.OwnedBy != "" {
+			sb.WriteString(fmt.Sprintf("ALTER SEQUENCE %s.%s OWNED BY %s;\n", seq.Schema, seq.Name, seq.OwnedBy))
 		}
+	}
 
-		sb.WriteString("\n")
+	if len(sc.Owners) > 0 || len(sc.Privileges) > 0 {
+		sb.WriteString(fmt.Sprintf("\n-- Privileges: %s\n", sc.Name))
+		for _, owner := range sc.Owners {
+			sb.WriteString(owner.ToSQL(sc.Name))
+		}
+		for _, privilege := range sc.Privileges {
+			sb.WriteString(privilege.ToSQL(sc.Name))
+		}
 	}
 
 	// if sc.Name != "public" {
@@ -204,38 +455,137 @@ func (col *Column) ToSQL() string {
 	parts = append(parts, col.Name)
 	parts = append(parts, col.DataType)
 
+	// COLLATE has to come straight after the type
+	if col.Collation != "" {
+		parts = append(parts, "COLLATE "+quoteIdentifier(col.Collation))
+	}
+
 	if !col.IsNullable {
 		parts = append(parts, "NOT NULL")
 	}
 
-	parts = append(parts, col.DefaultValue)
-	parts = append(parts, col.Comment)
+	if col.Generated != "" {
+		kind := "STORED"
+		if col.Virtual {
+			kind = "VIRTUAL"
+		}
+		parts = append(parts, fmt.Sprintf("GENERATED ALWAYS AS (%s) %s", col.Generated, kind))
+	} else if col.DefaultValue != "" {
+		parts = append(parts, "DEFAULT "+col.DefaultValue)
+	}
+
+	if col.Identity != "" {
+		parts = append(parts, fmt.Sprintf("GENERATED %s AS IDENTITY", col.Identity))
+	}
 
 	return strings.Join(parts, " ")
 }
 
-func (t *Table) ToSQL() string {
-	var sb strings.Builder
+var (
+	typeModifierRegex = regexp.MustCompile(`\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\)`)
+	arraySuffixRegex  = regexp.MustCompile(`\[\s*\d*\s*\]`)
+)
 
-	sb.WriteString(fmt.Sprintf("CREATE TABLE %s.%s (\n", t.Schema, t.Name))
+// SetDataType changes the column's type, deriving its length, precision, scale and array
+// dimensions from the modifiers written in dataType, e.g. "character varying(50)" or "numeric(10,2)[]"
+func (col *Column) SetDataType(dataType string) {
+	col.DataType = dataType
+	col.Length, col.Precision, col.Scale = 0, 0, 0
+	col.ArrayDims = len(arraySuffixRegex.FindAllString(dataType, -1))
+
+	m := typeModifierRegex.FindStringSubmatch(dataType)
+	if m == nil {
+		return
+	}
 
-	for i, col := range t.Columns {
-		sb.WriteString("  " + col.ToSQL())
-		if i < len(t.Columns)-1 || len(t.Constraints) > 0 {
-			sb.WriteString(",")
+	n, _ := strconv.Atoi(m[1])
+	base := col.BaseType()
+	switch {
+	case strings.HasPrefix(base, "char"), strings.HasPrefix(base, "varchar"),
+		strings.HasPrefix(base, "bit"), strings.HasPrefix(base, "varbit"), base == "bpchar":
+		col.Length = n
+	default:
+		col.Precision = n
+		if m[2] != "" {
+			col.Scale, _ = strconv.Atoi(m[2])
 		}
-		sb.WriteString("\n")
 	}
+}
 
-	for i, constraint := range t.Constraints {
-		sb.WriteString(fmt.Sprintf("  %s", constraint.ToSQL()))
-		if i > len(t.Columns)-1 {
-			sb.WriteString(",")
+// BaseType returns the column's type without modifiers or array dimensions, lowercased
+func (col *Column) BaseType() string {
+	base := typeModifierRegex.ReplaceAllString(col.DataType, "")
+	base = arraySuffixRegex.ReplaceAllString(base, "")
+	return strings.ToLower(strings.Join(strings.Fields(base), " "))
+}
+
+// partitionOrder orders tables so that every partition comes after its parent, keeping the
+// original order otherwise
+func partitionOrder(tables []*Table) []*Table {
+	ordered := make([]*Table, 0, len(tables))
+	placed := make(map[string]bool)
+
+	for len(ordered) < len(tables) {
+		progress := false
+		for _, t := range tables {
+			if placed[t.Name] {
+				continue
+			}
+
+			// a partition whose parent isn't in the list has nothing to wait for
+			parentPending := false
+			for _, parent := range tables {
+				if parent.Name == t.PartitionOf && !placed[parent.Name] {
+					parentPending = true
+				}
+			}
+			if !parentPending {
+				ordered = append(ordered, t)
+				placed[t.Name] = true
+				progress = true
+			}
 		}
-		sb.WriteString("\n")
+
+		if !progress {
+			break
+		}
+	}
+
+	return ordered
+}
+
+func (t *Table) ToSQL() string {
+	var sb strings.Builder
+
+	if t.IsPartition() {
+		// a partition's columns and constraints come from its parent
+		sb.WriteString(fmt.Sprintf("CREATE TABLE %s.%s PARTITION OF %s.%s %s", t.Schema, t.Name, t.Schema, t.PartitionOf, t.PartitionBound))
+	} else {
+		sb.WriteString(fmt.Sprintf("CREATE TABLE %s.%s (\n", t.Schema, t.Name))
+
+		for i, col := range t.Columns {
+			sb.WriteString("  " + col.ToSQL())
+			if i < len(t.Columns)-1 || len(t.Constraints) > 0 {
+				sb.WriteString(",")
+			}
+			sb.WriteString("\n")
+		}
+
+		for i, constraint := range t.Constraints {
+			sb.WriteString(fmt.Sprintf("  %s", constraint.ToSQL()))
+			if i < len(t.Constraints)-1 {
+				sb.WriteString(",")
+			}
+			sb.WriteString("\n")
+		}
+
+		sb.WriteString(")")
 	}
 
-	sb.WriteString(");\n")
+	if t.PartitionStrategy != "" {
+		sb.WriteString(fmt.Sprintf(" PARTITION BY %s (%s)", t.PartitionStrategy, t.PartitionKey))
+	}
+	sb.WriteString(";\n")
 
 	if t.Comment != "" {
 		sb.WriteString(fmt.Sprintf("COMMENT ON TABLE %s.%s IS '%s';\n", t.Schema, t.Name, t.Comment))
@@ -247,6 +597,41 @@ func (t *Table) ToSQL() string {
 		}
 	}
 
+	if t.RowSecurity {
+		sb.WriteString(fmt.Sprintf("ALTER TABLE %s.%s ENABLE ROW LEVEL SECURITY;\n", t.Schema, t.Name))
+	}
+	if t.ForceRowSecurity {
+		sb.WriteString(fmt.Sprintf("ALTER TABLE %s.%s FORCE ROW LEVEL SECURITY;\n", t.Schema, t.Name))
+	}
+
+	for _, policy := range t.Policies {
+		sb.WriteString(policy.ToSQL(t.Schema))
+	}
+
+	return sb.String()
+}
+
+func (pol *Policy) ToSQL(schema string) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("CREATE POLICY %s ON %s.%s", pol.Name, schema, pol.Table))
+	if pol.Restrictive {
+		sb.WriteString(" AS RESTRICTIVE")
+	}
+	if pol.Command != "" {
+		sb.WriteString(" FOR " + pol.Command)
+	}
+	if len(pol.Roles) > 0 {
+		sb.WriteString(" TO " + strings.Join(pol.Roles, ", "))
+	}
+	if pol.Using != "" {
+		sb.WriteString(fmt.Sprintf(" USING (%s)", pol.Using))
+	}
+	if pol.WithCheck != "" {
+		sb.WriteString(fmt.Sprintf(" WITH CHECK (%s)", pol.WithCheck))
+	}
+	sb.WriteString(";\n")
+
 	return sb.String()
 }
 
@@ -258,7 +643,7 @@ func (con *Constraint) ToSQL() string {
 	var sb strings.Builder
 
 	if con.Name != "" {
-		sb.WriteString(fmt.Sprintf("CONSTRAINT %s", con.Name))
+		sb.WriteString(fmt.Sprintf("CONSTRAINT %s ", con.Name))
 	}
 
 	switch con.Type {
@@ -266,69 +651,256 @@ func (con *Constraint) ToSQL() string {
 		sb.WriteString(fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(con.Columns, ", "))) //strings.Join(con.Columns, ", "))) accounts for composite keys
 	case FOREIGN_KEY:
 		sb.WriteString(fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s", strings.Join(con.Columns, ", "), con.References))
+		if con.OnUpdate != "" {
+			sb.WriteString(" ON UPDATE " + con.OnUpdate)
+		}
+		if con.OnDelete != "" {
+			sb.WriteString(" ON DELETE " + con.OnDelete)
+		}
 	case CHECK:
 		sb.WriteString(fmt.Sprintf("CHECK (%s)", con.CheckExpr))
 	case UNIQUE:
 		sb.WriteString(fmt.Sprintf("UNIQUE (%s)", strings.Join(con.Columns, ", ")))
+	case EXCLUDE:
+		sb.WriteString(con.Definition)
 	default:
 		sb.WriteString("-- Unknown constraint type")
 
 	}
 
+	if con.Deferrable {
+		sb.WriteString(" DEFERRABLE")
+		if con.InitiallyDeferred {
+			sb.WriteString(" INITIALLY DEFERRED")
+		}
+	}
+
 	return sb.String()
 }
 
 func (i *Index) ToSQL() string {
 	if i.Definition != "" {
-		return i.Definition + ";\n"
-
+		return i.Definition
 	}
 
 	var sb strings.Builder
 
-	sb.WriteString("CREATE")
+	sb.WriteString("CREATE ")
 
 	if i.IsUnique {
-		sb.WriteString("UNIQUE")
+		sb.WriteString("UNIQUE ")
+	}
 
+	keys := make([]string, 0, len(i.Columns))
+	for n, col := range i.Columns {
+		key := col
+		if n < len(i.OpClasses) && i.OpClasses[n] != "" {
+			key += " " + i.OpClasses[n]
+		}
+		if n < len(i.SortOrders) && i.SortOrders[n] != "" {
+			key += " " + i.SortOrders[n]
+		}
+		keys = append(keys, key)
 	}
 
 	sb.WriteString(fmt.Sprintf("INDEX %s ON %s.%s USING %s (%s)",
-		i.Name, i.Schema, i.Table, i.Method, strings.Join(i.Columns, ", ")))
+		i.Name, i.Schema, i.Table, i.Method, strings.Join(keys, ", ")))
+
+	if len(i.Include) > 0 {
+		sb.WriteString(fmt.Sprintf(" INCLUDE (%s)", strings.Join(i.Include, ", ")))
+	}
+
+	if i.Where != "" {
+		sb.WriteString(fmt.Sprintf(" WHERE %s", i.Where))
+	}
 
 	return sb.String()
 }
 
+var functionCallRegex = regexp.MustCompile(`(?s)^[\w."]+\s*\(.*\)$`)
+
 func (tr *Trigger) ToSQL() string {
 	var sb strings.Builder
 
-	sb.WriteString(fmt.Sprintf("CREATE TRIGGER %s\n%s %s ON %s.%s",
+	sb.WriteString(fmt.Sprintf("CREATE TRIGGER %s\n%s %s ON %s.%s\n",
 		tr.Name, tr.Timing, strings.Join(tr.Events, " OR "), tr.Schema, tr.Table))
 
-	sb.WriteString(fmt.Sprintf("EXECUTE PROCEDURE %s;\n", tr.Statement))
+	if tr.ForEach != "" {
+		sb.WriteString(fmt.Sprintf("FOR EACH %s\n", tr.ForEach))
+	}
+
+	if tr.When != "" {
+		sb.WriteString(fmt.Sprintf("WHEN (%s)\n", tr.When))
+	}
+
+	// MySQL and SQLite triggers run statements rather than call a function
+	switch {
+	case functionCallRegex.MatchString(tr.Statement):
+		sb.WriteString(fmt.Sprintf("EXECUTE FUNCTION %s;\n", tr.Statement))
+	case strings.HasPrefix(strings.ToUpper(tr.Statement), "BEGIN"):
+		sb.WriteString(fmt.Sprintf("%s;\n", strings.TrimSuffix(tr.Statement, ";")))
+	default:
+		sb.WriteString(fmt.Sprintf("BEGIN\n%s;\nEND;\n", tr.Statement))
+	}
+
+	switch tr.State {
+	case "DISABLED":
+		sb.WriteString(fmt.Sprintf("ALTER TABLE %s.%s DISABLE TRIGGER %s;\n", tr.Schema, tr.Table, tr.Name))
+	case "REPLICA", "ALWAYS":
+		sb.WriteString(fmt.Sprintf("ALTER TABLE %s.%s ENABLE %s TRIGGER %s;\n", tr.Schema, tr.Table, tr.State, tr.Name))
+	}
 
 	return sb.String()
 }
 
 func (fn *Function) ToSQL() string {
+	if fn.Definition != "" {
+		return strings.TrimRight(fn.Definition, " \n;") + ";\n"
+	}
+
+	var sb strings.Builder
+
+	kind := fn.Kind
+	if kind == "" {
+		kind = "FUNCTION"
+	}
+
+	sb.WriteString(fmt.Sprintf("CREATE OR REPLACE %s %s.%s(%s)\n", kind, fn.Schema, fn.Name, fn.Arguments))
+	if fn.ReturnType != "" {
+		sb.WriteString(fmt.Sprintf(" RETURNS %s\n", fn.ReturnType))
+	}
+	sb.WriteString(fmt.Sprintf(" LANGUAGE %s\n", fn.Language))
+
+	var attributes []string
+	if fn.Volatility != "" && fn.Volatility != "VOLATILE" {
+		attributes = append(attributes, fn.Volatility)
+	}
+	if fn.Strict {
+		attributes = append(attributes, "STRICT")
+	}
+	if fn.SecurityDefiner {
+		attributes = append(attributes, "SECURITY DEFINER")
+	}
+	if fn.Parallel != "" && fn.Parallel != "UNSAFE" {
+		attributes = append(attributes, "PARALLEL "+fn.Parallel)
+	}
+	if len(attributes) > 0 {
+		sb.WriteString(" " + strings.Join(attributes, " ") + "\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("AS $$%s$$;\n", fn.Body))
+
+	return sb.String()
+}
+
+// qualifiedObject names an object of schema the way GRANT and ALTER ... OWNER TO expect it
+func qualifiedObject(schema, objectType, object string) string {
+	if objectType == "SCHEMA" {
+		return "SCHEMA " + object
+	}
+	return fmt.Sprintf("%s %s.%s", objectType, schema, object)
+}
+
+func (priv *Privilege) ToSQL(schema string) string {
+	grantee := priv.Grantee
+	if grantee == "public" {
+		grantee = "PUBLIC"
+	}
+
+	if priv.Revoked {
+		return fmt.Sprintf("REVOKE %s ON %s FROM %s;\n", priv.Privilege, qualifiedObject(schema, priv.ObjectType, priv.Object), grantee)
+	}
+
+	sql := fmt.Sprintf("GRANT %s ON %s TO %s", priv.Privilege, qualifiedObject(schema, priv.ObjectType, priv.Object), grantee)
+	if priv.Grantable {
+		sql += " WITH GRANT OPTION"
+	}
+	return sql + ";\n"
+}
+
+func (o *Owner) ToSQL(schema string) string {
+	return fmt.Sprintf("ALTER %s OWNER TO %s;\n", qualifiedObject(schema, o.ObjectType, o.Object), o.Owner)
+}
+
+var bareIdentifierRegex = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// quoteIdentifier double-quotes name unless it's a valid bare identifier
+func quoteIdentifier(name string) string {
+	if bareIdentifierRegex.MatchString(name) {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (ext *Extension) ToSQL() string {
+	var sb strings.Builder
+
+	// names such as uuid-ossp aren't valid bare identifiers
+	sb.WriteString("CREATE EXTENSION IF NOT EXISTS " + quoteIdentifier(ext.Name))
+	if ext.Schema != "" {
+		sb.WriteString(" WITH SCHEMA " + ext.Schema)
+	}
+	if ext.Version != "" {
+		sb.WriteString(fmt.Sprintf(" VERSION '%s'", escapeString(ext.Version)))
+	}
+	sb.WriteString(";\n")
+
+	return sb.String()
+}
+
+func (en *Enum) ToSQL() string {
+	labels := make([]string, 0, len(en.Labels))
+	for _, label := range en.Labels {
+		labels = append(labels, "'"+escapeString(label)+"'")
+	}
+
+	return fmt.Sprintf("CREATE TYPE %s.%s AS ENUM (%s);\n", en.Schema, en.Name, strings.Join(labels, ", "))
+}
+
+func (d *Domain) ToSQL() string {
 	var sb strings.Builder
 
-	sb.WriteString(fmt.Sprintf("CREATE OR REPLACE FUNCTION %s.%s(%s)", fn.Schema, fn.Name, fn.Arguments))
-	sb.WriteString(fmt.Sprintf("RETURNS %s AS $$\n", fn.ReturnType))
-	sb.WriteString(fn.Definition)
-	sb.WriteString(fmt.Sprintf("\n$$ LANGUAGE %s;\n", fn.Language))
+	sb.WriteString(fmt.Sprintf("CREATE DOMAIN %s.%s AS %s", d.Schema, d.Name, d.BaseType))
+	if d.DefaultValue != "" {
+		sb.WriteString(" DEFAULT " + d.DefaultValue)
+	}
+	if d.NotNull {
+		sb.WriteString(" NOT NULL")
+	}
+	for _, con := range d.Constraints {
+		if con.Name != "" {
+			sb.WriteString(" CONSTRAINT " + con.Name)
+		}
+		sb.WriteString(fmt.Sprintf(" CHECK (%s)", con.CheckExpr))
+	}
+	sb.WriteString(";\n")
 
 	return sb.String()
 }
 
+func (ct *CompositeType) ToSQL() string {
+	attributes := make([]string, 0, len(ct.Attributes))
+	for _, attr := range ct.Attributes {
+		attributes = append(attributes, fmt.Sprintf("%s %s", attr.Name, attr.DataType))
+	}
+
+	return fmt.Sprintf("CREATE TYPE %s.%s AS (%s);\n", ct.Schema, ct.Name, strings.Join(attributes, ", "))
+}
+
 func (seq *Sequence) ToSQL() string {
 	var sb strings.Builder
 
 	sb.WriteString(fmt.Sprintf("CREATE SEQUENCE %s.%s\n", seq.Schema, seq.Name))
+	if seq.DataType != "" {
+		sb.WriteString(fmt.Sprintf("    AS %s\n", seq.DataType))
+	}
 	sb.WriteString(fmt.Sprintf("    START WITH %d\n", seq.Start))
 	sb.WriteString(fmt.Sprintf("    INCREMENT BY %d\n", seq.Increment))
 	sb.WriteString(fmt.Sprintf("    MINVALUE %d\n", seq.Min))
 	sb.WriteString(fmt.Sprintf("    MAXVALUE %d\n", seq.Max))
+	if seq.Cycle {
+		sb.WriteString("    CYCLE\n")
+	}
 	sb.WriteString(fmt.Sprintf("    CACHE %d;\n", seq.Cache))
 
 	return sb.String()
@@ -337,12 +909,31 @@ func (seq *Sequence) ToSQL() string {
 func (v *View) ToSQL() string {
 	var sb strings.Builder
 
-	sb.WriteString(fmt.Sprintf("CREATE OR REPLACE VIEW %s.%s AS\n%s;\n",
-		v.Schema, v.Name, v.Definition))
+	if v.Materialized {
+		withData := "WITH DATA"
+		if !v.WithData {
+			withData = "WITH NO DATA"
+		}
+
+		sb.WriteString(fmt.Sprintf("CREATE MATERIALIZED VIEW %s.%s AS\n%s\n%s;\n",
+			v.Schema, v.Name, v.Definition, withData))
+
+		for _, index := range v.Indexes {
+			sb.WriteString(index.ToSQL())
+			sb.WriteString(";\n")
+		}
+	} else {
+		sb.WriteString(fmt.Sprintf("CREATE OR REPLACE VIEW %s.%s AS\n%s;\n",
+			v.Schema, v.Name, v.Definition))
+	}
 
 	if v.Comment != "" {
-		sb.WriteString(fmt.Sprintf("COMMENT ON VIEW %s.%s IS '%s';\n",
-			v.Schema, v.Name, escapeString(v.Comment)))
+		kind := "VIEW"
+		if v.Materialized {
+			kind = "MATERIALIZED VIEW"
+		}
+		sb.WriteString(fmt.Sprintf("COMMENT ON %s %s.%s IS '%s';\n",
+			kind, v.Schema, v.Name, escapeString(v.Comment)))
 	}
 
 	return sb.String()
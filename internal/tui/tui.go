@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/Richd0tcom/schedrift/internal/models"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -44,6 +45,12 @@ var (
 
 	highlightStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#FF9800"))
+
+	progressDoneStyle = lipgloss.NewStyle().
+				Background(lipgloss.Color("#2A9D8F"))
+
+	progressTodoStyle = lipgloss.NewStyle().
+				Background(lipgloss.Color("#0D3B66"))
 )
 
 // Model represents the TUI model
@@ -60,6 +67,7 @@ type Model struct {
 	ready             bool
 	schemaFetching    bool //might be same as loading
 	connectionMessage string
+	progress          *models.ProgressEvent
 }
 
 type SchemaFetchedMsg struct {
@@ -70,6 +78,11 @@ type ConnectionMsg struct {
 	Message string
 }
 
+// ProgressMsg reports extraction progress
+type ProgressMsg struct {
+	Event models.ProgressEvent
+}
+
 // NewModel creates a new TUI model
 func NewModel() Model {
 	s := spinner.New()
@@ -223,6 +236,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case ConnectionMsg:
 		m.connectionMessage = msg.Message
 		return m, nil
+
+	case ProgressMsg:
+		m.progress = &msg.Event
+		// the progress view's spinner runs for as long as the extraction does
+		if msg.Event.Stage == models.ExtractionStarted {
+			return m, m.spinner.Tick
+		}
+		return m, nil
 	}
 
 	// Handle viewport updates
@@ -262,6 +283,8 @@ func (m Model) View() string {
 		footer = infoStyle.Render(loadingText)
 	} else if m.error != nil {
 		footer = errorStyle.Render(fmt.Sprintf("Error: %v", m.error))
+	} else if m.schema == nil && m.progress != nil {
+		footer = m.progressView()
 	} else {
 		footer = infoStyle.Render("Press q to quit")
 	}
@@ -270,6 +293,22 @@ func (m Model) View() string {
 	return fmt.Sprintf("%s\n%s\n%s\n%s", header, connInfo, content, footer)
 }
 
+// progressView renders the latest progress event as a progress bar
+func (m Model) progressView() string {
+	ev := m.progress
+	status := fmt.Sprintf(" %d of %d tables  %s  %s", ev.TablesDone, ev.Tables, ev.Elapsed.Round(time.Second), ev)
+
+	// the bar takes whatever width the status leaves, within reason
+	width := min(max(m.width-lipgloss.Width(status)-4, 10), 50)
+	filled := 0
+	if ev.Tables > 0 {
+		filled = width * ev.TablesDone / ev.Tables
+	}
+
+	bar := progressDoneStyle.Render(strings.Repeat(" ", filled)) + progressTodoStyle.Render(strings.Repeat(" ", width-filled))
+	return fmt.Sprintf("%s %s%s", m.spinner.View(), bar, status)
+}
+
 // buildSchemaView creates a text representation of the schema
 func buildSchemaView(dbSchema *models.DatabaseSchema) string {
 	var builder strings.Builder
@@ -311,14 +350,24 @@ func buildSchemaView(dbSchema *models.DatabaseSchema) string {
 			}
 
 			// Indexes
-			if len(schema.Indexes) > 0 {
+			var indexes []*models.Index
+			for _, index := range schema.Indexes {
+				if index.Table == table.Name {
+					indexes = append(indexes, index)
+				}
+			}
+			if len(indexes) > 0 {
 				builder.WriteString("    Indexes:\n")
-				for _, index := range schema.Indexes {
+				for _, index := range indexes {
 					unique := ""
 					if index.IsUnique {
 						unique = "UNIQUE "
 					}
-					builder.WriteString(fmt.Sprintf("    - %s%s (%s)\n", unique, index.Name, strings.Join(index.Columns, ", ")))
+					invalid := ""
+					if !index.IsValid {
+						invalid = " " + warningStyle.Render("INVALID")
+					}
+					builder.WriteString(fmt.Sprintf("    - %s%s (%s)%s\n", unique, index.Name, strings.Join(index.Columns, ", "), invalid))
 				}
 			}
 		}
@@ -54,7 +54,20 @@ func (ld *SchemaLoader) LoadFromDir(dir string) (*models.Schema, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to find schema file: %w", err)
 	}
-	return ld.parser.Parse(file)
+	return ld.LoadFromFile(file)
+}
+
+// LoadFromPath loads a schema from a file, or from the most likely schema file when path is a directory
+func (ld *SchemaLoader) LoadFromPath(path string) (*models.Schema, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	if info.IsDir() {
+		return ld.LoadFromDir(path)
+	}
+	return ld.LoadFromFile(path)
 }
 
 func (ld *SchemaLoader) findSchemaFile(repoDir string) (string, error){
@@ -251,11 +264,21 @@ func (sl *SchemaLoader) ValidateSchema(schema *models.Schema) error {
 
 func (ld *SchemaLoader) NormalizeSchema(schema *models.Schema) *models.Schema {
 	normalized := &models.Schema{
+		Name:        schema.Name,
 		Tables:      make([]*models.Table,0),
+		Views:       make([]*models.View, 0),
+		Triggers:    make([]*models.Trigger, 0),
 		Indexes:     make([]*models.Index, 0 ),
 		Sequences:   make([]*models.Sequence,0),
 		Functions:   make([]*models.Function, 0),
 
+		Enums:          make([]*models.Enum, 0),
+		Domains:        make([]*models.Domain, 0),
+		CompositeTypes: make([]*models.CompositeType, 0),
+		Extensions:     make([]*models.Extension, 0),
+		Privileges:     make([]*models.Privilege, 0),
+		Owners:         make([]*models.Owner, 0),
+
 		//TODO: add others
 	}
 
@@ -263,33 +286,214 @@ func (ld *SchemaLoader) NormalizeSchema(schema *models.Schema) *models.Schema {
 	for _, table := range schema.Tables {
 		normalizedTable := ld.normalizeTable(table)
 		normalized.Tables = append(normalized.Tables, normalizedTable)
+		normalized.Sequences = append(normalized.Sequences, ld.expandSerialColumns(schema.Name, normalizedTable)...)
 	}
 
 	// Copy and normalize other objects
 	for _, index := range schema.Indexes {
-		index.Name = strings.ToLower(index.Name)
+		index.Name = strings.ToLower(unqualify(index.Name))
+		index.Table = strings.ToLower(unqualify(index.Table))
 		normalized.Indexes = append(normalized.Indexes, index)
 	}
 
+	for _, view := range schema.Views {
+		view.Name = strings.ToLower(unqualify(view.Name))
+		normalized.Views = append(normalized.Views, view)
+	}
+
+	for _, trigger := range schema.Triggers {
+		trigger.Name = strings.ToLower(unqualify(trigger.Name))
+		trigger.Table = strings.ToLower(unqualify(trigger.Table))
+		normalized.Triggers = append(normalized.Triggers, trigger)
+	}
 
 
 	for _, sequence := range schema.Sequences {
-		sequence.Name = strings.ToLower(sequence.Name)	
+		sequence.Name = strings.ToLower(unqualify(sequence.Name))
+		// OWNED BY names a column, which is only qualified by its schema when it has three parts
+		if strings.Count(sequence.OwnedBy, ".") > 1 {
+			_, sequence.OwnedBy, _ = strings.Cut(sequence.OwnedBy, ".")
+		}
+		sequence.OwnedBy = strings.ToLower(sequence.OwnedBy)
 		normalized.Sequences = append(normalized.Sequences, sequence)
 	}
 
 	for _, function := range schema.Functions {
-		function.Name = strings.ToLower(function.Name)
+		function.Name = strings.ToLower(unqualify(function.Name))
+		function.Arguments = ld.normalizeArguments(function.Arguments)
+		if !strings.HasPrefix(strings.ToUpper(function.ReturnType), "TABLE") {
+			function.ReturnType = ld.normalizeType(typmodRegex.ReplaceAllString(function.ReturnType, ""))
+		}
 		normalized.Functions = append(normalized.Functions, function)
 	}
 
+	for _, ext := range schema.Extensions {
+		ext.Name = strings.ToLower(ext.Name)
+		ext.Schema = strings.ToLower(ext.Schema)
+		normalized.Extensions = append(normalized.Extensions, ext)
+	}
+
+	for _, privilege := range schema.Privileges {
+		privilege.Object = ld.normalizeObject(privilege.ObjectType, privilege.Object)
+		privilege.Grantee = strings.ToLower(privilege.Grantee)
+		privilege.Privilege = strings.ToUpper(privilege.Privilege)
+		normalized.Privileges = append(normalized.Privileges, privilege)
+	}
+
+	for _, owner := range schema.Owners {
+		owner.Object = ld.normalizeObject(owner.ObjectType, owner.Object)
+		owner.Owner = strings.ToLower(owner.Owner)
+		normalized.Owners = append(normalized.Owners, owner)
+	}
+
+	// enum labels are case-sensitive and are kept as written
+	for _, enum := range schema.Enums {
+		enum.Name = strings.ToLower(enum.Name)
+		normalized.Enums = append(normalized.Enums, enum)
+	}
+
+	for _, domain := range schema.Domains {
+		domain.Name = strings.ToLower(domain.Name)
+		domain.BaseType = ld.normalizeType(domain.BaseType)
+		for _, constraint := range domain.Constraints {
+			constraint.Name = strings.ToLower(constraint.Name)
+		}
+		normalized.Domains = append(normalized.Domains, domain)
+	}
+
+	for _, composite := range schema.CompositeTypes {
+		composite.Name = strings.ToLower(composite.Name)
+		for _, attr := range composite.Attributes {
+			attr.Name = strings.ToLower(attr.Name)
+			attr.DataType = ld.normalizeType(attr.DataType)
+		}
+		normalized.CompositeTypes = append(normalized.CompositeTypes, composite)
+	}
+
 	return normalized
 }
 
+// typePrefixes are the first words of multi-word types, which can't be argument names
+var typePrefixes = map[string]bool{
+	"bit":       true,
+	"char":      true,
+	"character": true,
+	"double":    true,
+	"interval":  true,
+	"national":  true,
+	"time":      true,
+	"timestamp": true,
+}
+
+// normalizeObject normalizes the name of an object that privileges or an owner apply to.
+// Routines are reduced to their name and input argument types, which is all GRANT needs
+// to tell overloads apart
+func (ld *SchemaLoader) normalizeObject(objectType, object string) string {
+	name, args, isRoutine := strings.Cut(object, "(")
+	if !isRoutine || (objectType != "FUNCTION" && objectType != "PROCEDURE") {
+		return strings.ToLower(unqualify(object))
+	}
+	name = unqualify(name)
+
+	var types []string
+	for _, arg := range strings.Split(ld.normalizeArguments(strings.TrimSuffix(args, ")")), ",") {
+		fields := strings.Fields(arg)
+		if len(fields) == 0 || fields[0] == "OUT" {
+			continue
+		}
+		if fields[0] == "INOUT" || fields[0] == "VARIADIC" {
+			fields = fields[1:]
+		}
+		if len(fields) > 1 && !typePrefixes[fields[0]] {
+			fields = fields[1:]
+		}
+		types = append(types, ld.normalizeType(strings.Join(fields, " ")))
+	}
+
+	return fmt.Sprintf("%s(%s)", strings.ToLower(name), strings.Join(types, ", "))
+}
+
+// unqualify strips the schema from a qualified name like public.users. Dumps qualify their
+// objects, while the extractors name them relative to the schema they were extracted from
+func unqualify(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// unqualifyReference strips the schema from a foreign key's referenced table, as in public.users(id)
+func unqualifyReference(ref string) string {
+	table, columns, found := strings.Cut(ref, "(")
+	if !found {
+		return unqualify(ref)
+	}
+	return unqualify(table) + "(" + columns
+}
+
+// WithoutOwners returns a copy of schema with no object owners, so that ownership isn't compared
+func (ld *SchemaLoader) WithoutOwners(schema *models.Schema) *models.Schema {
+	stripped := *schema
+	stripped.Owners = make([]*models.Owner, 0)
+	return &stripped
+}
+
+// FilterTables returns a copy of schema without the tables rejected by match, along with
+// the indexes, triggers, owned sequences, privileges and owners that belong to them. The schema
+// is expected to be normalized, so that table names aren't qualified with their schema
+func (ld *SchemaLoader) FilterTables(schema *models.Schema, match func(table string) bool) *models.Schema {
+	filtered := *schema
+	filtered.Tables = make([]*models.Table, 0, len(schema.Tables))
+	filtered.Indexes = make([]*models.Index, 0, len(schema.Indexes))
+	filtered.Triggers = make([]*models.Trigger, 0, len(schema.Triggers))
+	filtered.Sequences = make([]*models.Sequence, 0, len(schema.Sequences))
+	filtered.Privileges = make([]*models.Privilege, 0, len(schema.Privileges))
+	filtered.Owners = make([]*models.Owner, 0, len(schema.Owners))
+
+	removed := make(map[string]bool)
+	for _, table := range schema.Tables {
+		if match(table.Name) {
+			filtered.Tables = append(filtered.Tables, table)
+		} else {
+			removed[table.Name] = true
+		}
+	}
+
+	for _, privilege := range schema.Privileges {
+		if privilege.ObjectType != "TABLE" || !removed[privilege.Object] {
+			filtered.Privileges = append(filtered.Privileges, privilege)
+		}
+	}
+
+	for _, owner := range schema.Owners {
+		if owner.ObjectType != "TABLE" || !removed[owner.Object] {
+			filtered.Owners = append(filtered.Owners, owner)
+		}
+	}
+
+	for _, index := range schema.Indexes {
+		if match(index.Table) {
+			filtered.Indexes = append(filtered.Indexes, index)
+		}
+	}
+
+	for _, trigger := range schema.Triggers {
+		if match(trigger.Table) {
+			filtered.Triggers = append(filtered.Triggers, trigger)
+		}
+	}
+
+	for _, sequence := range schema.Sequences {
+		owner, _, _ := strings.Cut(sequence.OwnedBy, ".")
+		if owner == "" || match(owner) {
+			filtered.Sequences = append(filtered.Sequences, sequence)
+		}
+	}
+
+	return &filtered
+}
+
 // normalizeTable normalizes a table for consistent comparison
 func (ld *SchemaLoader) normalizeTable(table *models.Table) *models.Table {
 	normalized := &models.Table{
-		Name:        strings.ToLower(table.Name),
+		Name:        strings.ToLower(unqualify(table.Name)),
 		Columns:     make([]*models.Column, 0),
 		Constraints: make([]*models.Constraint, 0),
 	}
@@ -298,46 +502,192 @@ func (ld *SchemaLoader) normalizeTable(table *models.Table) *models.Table {
 	for _ , column := range table.Columns {
 		normalizedCol := &models.Column{
 			Name:         strings.ToLower(column.Name),
-			DataType:         ld.normalizeType(column.DataType),
 			IsNullable:     column.IsNullable,
-			DefaultValue: column.DefaultValue,
+			DefaultValue: normalizeDefault(column.DefaultValue),
+			Identity:     column.Identity,
+			Collation:    column.Collation,
+			Generated:    column.Generated,
+			Virtual:      column.Virtual,
 		}
+		normalizedCol.SetDataType(ld.normalizeType(column.DataType))
 		normalized.Columns = append(normalized.Columns, normalizedCol)
 	}
 
 	for _, constraint := range table.Constraints {
-		constraint.Name = strings.ToLower(constraint.Name)
+		// the parser names unnamed constraints after the table, which may be qualified
+		constraint.Name = strings.ToLower(unqualify(constraint.Name))
+		constraint.References = unqualifyReference(constraint.References)
 		normalized.Constraints = append(normalized.Constraints, constraint)
 	}
 
+	normalized.PartitionStrategy = strings.ToUpper(table.PartitionStrategy)
+	normalized.PartitionKey = table.PartitionKey
+	normalized.PartitionOf = strings.ToLower(unqualify(table.PartitionOf))
+	normalized.PartitionBound = table.PartitionBound
+
+	normalized.Engine = table.Engine
+	normalized.Charset = strings.ToLower(table.Charset)
+	normalized.Collation = strings.ToLower(table.Collation)
+
+	normalized.RowSecurity = table.RowSecurity
+	normalized.ForceRowSecurity = table.ForceRowSecurity
+	for _, policy := range table.Policies {
+		policy.Name = strings.ToLower(policy.Name)
+		policy.Table = normalized.Name
+		for i, role := range policy.Roles {
+			policy.Roles[i] = strings.ToLower(role)
+		}
+		normalized.Policies = append(normalized.Policies, policy)
+	}
+
 	return normalized
 }
 
+// serialTypes maps the serial pseudo-types to the column type PostgreSQL creates for them
+var serialTypes = map[string]string{
+	"smallserial": "smallint",
+	"serial2":     "smallint",
+	"serial":      "integer",
+	"serial4":     "integer",
+	"bigserial":   "bigint",
+	"serial8":     "bigint",
+}
+
+// expandSerialColumns rewrites serial columns the way PostgreSQL stores them: a NOT NULL
+// integer column defaulting to nextval() of an implicitly created sequence it owns
+func (ld *SchemaLoader) expandSerialColumns(schemaName string, table *models.Table) []*models.Sequence {
+	var sequences []*models.Sequence
+
+	for _, column := range table.Columns {
+		dataType, ok := serialTypes[column.DataType]
+		if !ok {
+			continue
+		}
+
+		seqName := fmt.Sprintf("%s_%s_seq", table.Name, column.Name)
+		seq := models.NewSequence(schemaName, seqName, dataType)
+		seq.OwnedBy = table.Name + "." + column.Name
+		sequences = append(sequences, seq)
+
+		column.DataType = dataType
+		column.IsNullable = false
+		column.DefaultValue = fmt.Sprintf("nextval('%s'::regclass)", seqName)
+	}
+
+	return sequences
+}
+
+// literalCastRegex matches a string literal cast to a type, as in 'draft'::text or
+// '2024-01-01'::timestamp without time zone
+var literalCastRegex = regexp.MustCompile(`^('(?:[^']|'')*')::[\w\s."\[\]()]+$`)
+
+// normalizeDefault normalizes a column default for comparison. PostgreSQL casts string literals
+// to the column's type, while reference files, SQLite and MySQL usually don't, so the cast and
+// the quotes are dropped
+func normalizeDefault(value string) string {
+	value = strings.TrimSpace(value)
+	if m := literalCastRegex.FindStringSubmatch(value); m != nil {
+		value = m[1]
+	}
+	if len(value) >= 2 && strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") {
+		value = strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	}
+	return value
+}
+
+// typmodRegex matches type modifiers such as (10, 2), which PostgreSQL doesn't keep on function signatures
+var typmodRegex = regexp.MustCompile(`\s*\(\s*\d+(?:\s*,\s*\d+)?\s*\)`)
+
+// normalizeArguments normalizes a function's identity arguments to the form PostgreSQL reports them in
+func (ld *SchemaLoader) normalizeArguments(args string) string {
+	args = typmodRegex.ReplaceAllString(args, "")
+	if strings.TrimSpace(args) == "" {
+		return ""
+	}
+
+	var normalized []string
+	for _, arg := range strings.Split(args, ",") {
+		fields := strings.Fields(strings.ToLower(arg))
+
+		var parts []string
+		if len(fields) > 1 && (fields[0] == "out" || fields[0] == "inout" || fields[0] == "variadic") {
+			parts = append(parts, strings.ToUpper(fields[0]))
+			fields = fields[1:]
+		}
+
+		// the first word is the argument's name unless the argument is just a type
+		if len(fields) > 1 {
+			parts = append(parts, fields[0])
+			fields = fields[1:]
+		}
+		parts = append(parts, ld.normalizeType(strings.Join(fields, " ")))
+
+		normalized = append(normalized, strings.Join(parts, " "))
+	}
+
+	return strings.Join(normalized, ", ")
+}
+
 // normalizeType normalizes a PostgreSQL data type for consistent comparison
 func (ld *SchemaLoader) normalizeType(dataType string) string {
 	// Remove extra whitespace
 	normalized := strings.TrimSpace(dataType)
-	normalized = regexp.MustCompile(`\s+`).ReplaceAllString(normalized, " ")
+	normalized = whitespaceRegex.ReplaceAllString(normalized, " ")
 	
 	// Convert to lowercase for comparison
 	normalized = strings.ToLower(normalized)
 	
+	// modifiers and array brackets are written the way format_type renders them, e.g. numeric(10,2)[]
+	normalized = typeModifierRegex.ReplaceAllStringFunc(normalized, func(m string) string {
+		return strings.Join(strings.Fields(m), "")
+	})
+	normalized, arrays, _ := strings.Cut(normalized, "[")
+	if arrays != "" {
+		arrays = strings.Repeat("[]", strings.Count(arrays, "]"))
+	}
+
 	// Normalize common type aliases
 	typeAliases := map[string]string{
-		"int":     "integer",
-		"int4":    "integer",
-		"int8":    "bigint",
-		"float4":  "real",
-		"float8":  "double precision",
-		"bool":    "boolean",
-		"varchar": "character varying",
+		"int":          "integer",
+		"int2":         "smallint",
+		"int4":         "integer",
+		"int8":         "bigint",
+		"float":        "double precision",
+		"float4":       "real",
+		"float8":       "double precision",
+		"decimal":      "numeric",
+		"bool":         "boolean",
+		"char":         "character",
+		"char varying": "character varying",
+		"varchar":      "character varying",
+		"varbit":       "bit varying",
 	}
 	
+	// only replace whole type names so that "int" doesn't rewrite "integer" or "interval"
 	for alias, canonical := range typeAliases {
-		if strings.HasPrefix(normalized, alias) {
-			normalized = strings.Replace(normalized, alias, canonical, 1)
+		if normalized == alias || strings.HasPrefix(normalized, alias+"(") {
+			normalized = canonical + normalized[len(alias):]
+			break
 		}
 	}
+
+	// time types default to WITHOUT TIME ZONE, and the time zone comes after the precision
+	if m := timeTypeRegex.FindStringSubmatch(normalized); m != nil {
+		zone := m[4]
+		switch {
+		case m[2] != "":
+			zone = " with time zone"
+		case zone == "":
+			zone = " without time zone"
+		}
+		normalized = m[1] + m[3] + zone
+	}
 	
-	return normalized
-}
\ No newline at end of file
+	return normalized + arrays
+}
+
+var (
+	whitespaceRegex   = regexp.MustCompile(`\s+`)
+	typeModifierRegex = regexp.MustCompile(`\s*\(\s*\d+\s*(?:,\s*\d+\s*)?\)`)
+	timeTypeRegex     = regexp.MustCompile(`^(timestamp|time)(tz)?(\(\d+\))?( with(?:out)? time zone)?$`)
+)
@@ -0,0 +1,121 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Richd0tcom/schedrift/internal/diff"
+	"github.com/Richd0tcom/schedrift/internal/models"
+)
+
+// extractedSchema builds a schema the way the extractors report it, with unqualified names
+func extractedSchema() *models.Schema {
+	users := &models.Table{
+		Name:   "users",
+		Schema: "public",
+		Columns: []*models.Column{
+			{Name: "id", DataType: "bigint", Identity: "BY DEFAULT"},
+			{Name: "email", DataType: "character varying(255)"},
+			{Name: "age", DataType: "integer", IsNullable: true},
+		},
+		Constraints: []*models.Constraint{
+			{Name: "users_pkey", Type: models.PRIMARY_KEY, Columns: []string{"id"}},
+			{Name: "users_email_key", Type: models.UNIQUE, Columns: []string{"email"}},
+			{Name: "users_age_check", Type: models.CHECK, CheckExpr: "age >= 0"},
+		},
+	}
+	for _, column := range users.Columns {
+		column.SetDataType(column.DataType)
+	}
+
+	posts := &models.Table{
+		Name:   "posts",
+		Schema: "public",
+		Columns: []*models.Column{
+			{Name: "id", DataType: "integer"},
+			{Name: "user_id", DataType: "bigint", IsNullable: true},
+			{Name: "title", DataType: "text", DefaultValue: "'untitled'::text"},
+		},
+		Constraints: []*models.Constraint{
+			{Name: "posts_pkey", Type: models.PRIMARY_KEY, Columns: []string{"id"}},
+			{Name: "posts_user_id_fkey", Type: models.FOREIGN_KEY, Columns: []string{"user_id"}, References: "users(id)", OnDelete: "CASCADE"},
+		},
+	}
+	for _, column := range posts.Columns {
+		column.SetDataType(column.DataType)
+	}
+
+	return &models.Schema{
+		Name:   "public",
+		Tables: []*models.Table{users, posts},
+		Indexes: []*models.Index{{
+			Name:    "idx_posts_user",
+			Schema:  "public",
+			Table:   "posts",
+			Columns: []string{"user_id"},
+			IsValid: true,
+			Method:  "btree",
+		}},
+		Triggers: []*models.Trigger{{
+			Name:      "posts_audit",
+			Schema:    "public",
+			Table:     "posts",
+			Events:    []string{"INSERT"},
+			Timing:    "AFTER",
+			ForEach:   "ROW",
+			State:     "ENABLED",
+			Statement: "audit()",
+		}},
+		Owners: []*models.Owner{{ObjectType: "TABLE", Object: "posts", Owner: "app_owner"}},
+		Privileges: []*models.Privilege{
+			{ObjectType: "TABLE", Object: "posts", Grantee: "reporting", Privilege: "SELECT"},
+			{ObjectType: "TABLE", Object: "posts", Grantee: "app_owner", Privilege: "TRUNCATE", Revoked: true},
+		},
+	}
+}
+
+// TestDumpRoundTrip checks that a dump of a schema is reported as identical to the schema it
+// was dumped from, even though the dump qualifies every table with its schema
+func TestDumpRoundTrip(t *testing.T) {
+	dump := &models.DatabaseSchema{Name: "app", Schemas: []*models.Schema{extractedSchema()}}
+
+	path := filepath.Join(t.TempDir(), "schema.sql")
+	if err := os.WriteFile(path, []byte(dump.ToSQL()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ld := NewSchemaLoader(&LoaderConfig{})
+	reference, err := ld.LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("failed to load the dump: %v", err)
+	}
+
+	schemaDiff := diff.BuildDiff(ld.NormalizeSchema(reference), ld.NormalizeSchema(extractedSchema()))
+	if schemaDiff.HasChanges() {
+		t.Errorf("the dump differs from the schema it was dumped from:\n%s", schemaDiff.ToText())
+	}
+}
+
+func TestFilterTablesQualifiedReference(t *testing.T) {
+	ld := NewSchemaLoader(&LoaderConfig{})
+	reference, err := ld.parser.Parse(`
+		CREATE TABLE public.users (id integer PRIMARY KEY);
+		CREATE TABLE public.audit_log (id integer);
+		CREATE INDEX idx_audit ON public.audit_log (id);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filtered := ld.FilterTables(ld.NormalizeSchema(reference), func(table string) bool {
+		return table != "audit_log"
+	})
+
+	if len(filtered.Tables) != 1 || filtered.Tables[0].Name != "users" {
+		t.Errorf("expected only users to be kept, got %v", filtered.Tables)
+	}
+	if len(filtered.Indexes) != 0 {
+		t.Errorf("expected the index on audit_log to be filtered out, got %v", filtered.Indexes)
+	}
+}
@@ -3,8 +3,10 @@ package parser
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/Richd0tcom/schedrift/internal/models"
 )
@@ -19,7 +21,79 @@ type SQLParser struct {
 	alterTableRegex  *regexp.Regexp
 	primaryKeyRegex  *regexp.Regexp
 	foreignKeyRegex  *regexp.Regexp
+	referencesRegex  *regexp.Regexp
+	onDeleteRegex    *regexp.Regexp
+	onUpdateRegex    *regexp.Regexp
 	uniqueRegex      *regexp.Regexp
+
+	triggerRegex        *regexp.Regexp
+	triggerEventsRegex  *regexp.Regexp
+	triggerForEachRegex *regexp.Regexp
+	triggerExecuteRegex *regexp.Regexp
+	triggerStateRegex   *regexp.Regexp
+	triggerStartRegex   *regexp.Regexp
+	triggerBodyRegex    *regexp.Regexp
+	blockKeywordRegex   *regexp.Regexp
+
+	alterSequenceRegex     *regexp.Regexp
+	identityRegex          *regexp.Regexp
+	sequenceTypeRegex      *regexp.Regexp
+	sequenceIncrementRegex *regexp.Regexp
+	sequenceMinRegex       *regexp.Regexp
+	sequenceMaxRegex       *regexp.Regexp
+	sequenceStartRegex     *regexp.Regexp
+	sequenceCacheRegex     *regexp.Regexp
+	sequenceCycleRegex     *regexp.Regexp
+	sequenceNoCycleRegex   *regexp.Regexp
+	sequenceOwnedByRegex   *regexp.Regexp
+
+	columnTypeRegex    *regexp.Regexp
+	collateRegex       *regexp.Regexp
+	generatedRegex     *regexp.Regexp
+	bareGeneratedRegex *regexp.Regexp
+	alterColumnRegex   *regexp.Regexp
+	setGeneratedRegex  *regexp.Regexp
+	defaultRegex       *regexp.Regexp
+
+	functionReturnsRegex    *regexp.Regexp
+	functionBodyRegex       *regexp.Regexp
+	dollarQuoteRegex        *regexp.Regexp
+	functionLanguageRegex   *regexp.Regexp
+	functionVolatilityRegex *regexp.Regexp
+	functionParallelRegex   *regexp.Regexp
+	functionStrictRegex     *regexp.Regexp
+	functionSecurityRegex   *regexp.Regexp
+	argumentDefaultRegex    *regexp.Regexp
+
+	createTypeRegex    *regexp.Regexp
+	domainRegex        *regexp.Regexp
+	domainCheckRegex   *regexp.Regexp
+	domainDefaultRegex *regexp.Regexp
+	domainNotNullRegex *regexp.Regexp
+	alterTypeRegex     *regexp.Regexp
+	quotedLiteralRegex *regexp.Regexp
+
+	extensionRegex        *regexp.Regexp
+	extensionSchemaRegex  *regexp.Regexp
+	extensionVersionRegex *regexp.Regexp
+
+	policyRegex          *regexp.Regexp
+	policyUsingRegex     *regexp.Regexp
+	policyWithCheckRegex *regexp.Regexp
+	policyOptionsRegex   *regexp.Regexp
+	rowSecurityRegex     *regexp.Regexp
+
+	grantRegex       *regexp.Regexp
+	revokeRegex      *regexp.Regexp
+	grantTargetRegex *regexp.Regexp
+	grantAllRegex    *regexp.Regexp
+	ownerToRegex     *regexp.Regexp
+
+	partitionOfRegex     *regexp.Regexp
+	partitionByRegex     *regexp.Regexp
+	partitionBoundRegex  *regexp.Regexp
+	attachPartitionRegex *regexp.Regexp
+	detachPartitionRegex *regexp.Regexp
 }
 
 func NewSQLParser() *SQLParser {
@@ -27,13 +101,91 @@ func NewSQLParser() *SQLParser {
 		createTableRegex: regexp.MustCompile(`(?i)CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([^\s(]+)\s*\(`),
 		columnRegex:      regexp.MustCompile(`(?i)^\s*([^\s,]+)\s+([^\s,]+(?:\([^)]+\))?)\s*(.*?)(?:,\s*$|$)`),
 		constraintRegex:  regexp.MustCompile(`(?i)CONSTRAINT\s+([^\s]+)\s+(.*)`),
-		indexRegex:       regexp.MustCompile(`(?i)CREATE\s+(?:(UNIQUE)\s+)?INDEX\s+(?:IF\s+NOT\s+EXISTS\s+)?([^\s]+)\s+ON\s+([^\s(]+)\s*(?:USING\s+([^\s(]+))?\s*\(([^)]+)\)`),
-		sequenceRegex:    regexp.MustCompile(`(?i)CREATE\s+SEQUENCE\s+(?:IF\s+NOT\s+EXISTS\s+)?([^\s]+)(?:\s+(.*))?`),
-		functionRegex:    regexp.MustCompile(`(?i)CREATE\s+(?:OR\s+REPLACE\s+)?FUNCTION\s+([^\s(]+)\s*\([^)]*\)\s+RETURNS\s+([^\s]+)(?:\s+LANGUAGE\s+([^\s]+))?`),
+		indexRegex:       regexp.MustCompile(`(?i)CREATE\s+(?:(UNIQUE)\s+)?INDEX\s+(?:CONCURRENTLY\s+)?(?:IF\s+NOT\s+EXISTS\s+)?([^\s]+)\s+ON\s+(?:ONLY\s+)?([^\s(]+)\s*(?:USING\s+([^\s(]+))?\s*\(`),
+		sequenceRegex:    regexp.MustCompile(`(?is)CREATE\s+SEQUENCE\s+(?:IF\s+NOT\s+EXISTS\s+)?([^\s]+)(?:\s+(.*))?`),
+		functionRegex:    regexp.MustCompile(`(?is)CREATE\s+(?:OR\s+REPLACE\s+)?(FUNCTION|PROCEDURE)\s+([^\s(]+)\s*\(`),
 		alterTableRegex:  regexp.MustCompile(`(?i)ALTER\s+TABLE\s+([^\s]+)\s+(.*)`),
 		primaryKeyRegex:  regexp.MustCompile(`(?i)PRIMARY\s+KEY\s*\(([^)]+)\)`),
 		foreignKeyRegex:  regexp.MustCompile(`(?i)FOREIGN\s+KEY\s*\(([^)]+)\)\s+REFERENCES\s+([^\s(]+)(?:\s*\(([^)]+)\))?`),
+		referencesRegex:  regexp.MustCompile(`(?i)REFERENCES\s+([^\s(]+)(?:\s*\(([^)]+)\))?`),
+		onDeleteRegex:    regexp.MustCompile(`(?i)ON\s+DELETE\s+(NO\s+ACTION|RESTRICT|CASCADE|SET\s+NULL|SET\s+DEFAULT)`),
+		onUpdateRegex:    regexp.MustCompile(`(?i)ON\s+UPDATE\s+(NO\s+ACTION|RESTRICT|CASCADE|SET\s+NULL|SET\s+DEFAULT)`),
 		uniqueRegex:      regexp.MustCompile(`(?i)UNIQUE\s*\(([^)]+)\)`),
+
+		triggerRegex:        regexp.MustCompile(`(?is)CREATE\s+(?:OR\s+REPLACE\s+)?(?:CONSTRAINT\s+|TEMP\s+|TEMPORARY\s+)?TRIGGER\s+(?:IF\s+NOT\s+EXISTS\s+)?(\S+)\s+(?:(BEFORE|AFTER|INSTEAD\s+OF)\s+)?(.+?)\s+ON\s+(\S+)(.*)$`),
+		triggerEventsRegex:  regexp.MustCompile(`(?i)\s+OR\s+`),
+		triggerForEachRegex: regexp.MustCompile(`(?i)FOR\s+(?:EACH\s+)?(ROW|STATEMENT)`),
+		triggerExecuteRegex: regexp.MustCompile(`(?is)EXECUTE\s+(?:FUNCTION|PROCEDURE)\s+(.+)$`),
+		triggerStateRegex:   regexp.MustCompile(`(?i)^(ENABLE|DISABLE)\s+(?:(REPLICA|ALWAYS)\s+)?TRIGGER\s+(\S+)`),
+		triggerStartRegex:   regexp.MustCompile(`(?is)^\s*CREATE\s+(?:TEMP\s+|TEMPORARY\s+)?TRIGGER\b`),
+		triggerBodyRegex:    regexp.MustCompile(`(?is)\bBEGIN\s+(.+?)\s*END\s*$`),
+		blockKeywordRegex:   regexp.MustCompile(`(?i)^(BEGIN|CASE|END)\b`),
+
+		alterSequenceRegex:     regexp.MustCompile(`(?is)ALTER\s+SEQUENCE\s+(?:IF\s+EXISTS\s+)?([^\s]+)\s+.*?OWNED\s+BY\s+(\S+)`),
+		identityRegex:          regexp.MustCompile(`(?i)GENERATED\s+(ALWAYS|BY\s+DEFAULT)\s+AS\s+IDENTITY(?:\s*\(([^)]*)\))?`),
+		sequenceTypeRegex:      regexp.MustCompile(`(?i)\bAS\s+(\w+)`),
+		sequenceIncrementRegex: regexp.MustCompile(`(?i)(?:^|\s)INCREMENT(?:\s+BY)?\s+([+-]?\d+)`),
+		sequenceMinRegex:       regexp.MustCompile(`(?i)(?:^|\s)MINVALUE\s+([+-]?\d+)`),
+		sequenceMaxRegex:       regexp.MustCompile(`(?i)(?:^|\s)MAXVALUE\s+([+-]?\d+)`),
+		sequenceStartRegex:     regexp.MustCompile(`(?i)(?:^|\s)START(?:\s+WITH)?\s+([+-]?\d+)`),
+		sequenceCacheRegex:     regexp.MustCompile(`(?i)(?:^|\s)CACHE\s+([+-]?\d+)`),
+		sequenceCycleRegex:     regexp.MustCompile(`(?i)(?:^|\s)CYCLE\b`),
+		sequenceNoCycleRegex:   regexp.MustCompile(`(?i)\bNO\s+CYCLE\b`),
+		sequenceOwnedByRegex:   regexp.MustCompile(`(?i)OWNED\s+BY\s+(\S+)`),
+
+		// multi-word types are listed first so that e.g. "double precision" isn't cut after "double"
+		columnTypeRegex:   regexp.MustCompile(`(?is)^\s*("[^"]+"|\S+)\s+((?:double\s+precision|(?:character|char|bit)\s+varying|(?:timestamp|time)(?:\s*\(\s*\d+\s*\))?\s+with(?:out)?\s+time\s+zone|[^\s(\[,]+)(?:\s*\([^)]*\))?(?:\s*\[\s*\d*\s*\])*)`),
+		collateRegex:      regexp.MustCompile(`(?i)\bCOLLATE\s+("[^"]+"|\S+)`),
+		alterColumnRegex:  regexp.MustCompile(`(?is)^ALTER\s+COLUMN\s+("[^"]+"|\S+)\s+(.+)$`),
+		setGeneratedRegex: regexp.MustCompile(`(?i)^SET\s+GENERATED\s+(ALWAYS|BY\s+DEFAULT)\b`),
+
+		// string literals are matched whole so that an AS ( inside a quoted default is skipped
+		generatedRegex: regexp.MustCompile(`(?i)'(?:[^']|'')*'|\bGENERATED\s+ALWAYS\s+AS\s*\(`),
+		// MySQL and SQLite allow GENERATED ALWAYS to be left out, but only right after the column type
+		bareGeneratedRegex: regexp.MustCompile(`(?i)^\s*AS\s*\(`),
+		// string literals are matched whole, as they may contain spaces and commas
+		defaultRegex: regexp.MustCompile(`(?i)DEFAULT\s+('(?:[^']|'')*'(?:::[\w.]+(?:\([^)]*\))?)?|[^,\s]+(?:\([^)]*\))?)`),
+
+		functionReturnsRegex: regexp.MustCompile(`(?is)^\s*RETURNS\s+(.+?)\s+(?:LANGUAGE|AS|IMMUTABLE|STABLE|VOLATILE|STRICT|CALLED|RETURNS|SECURITY|EXTERNAL|PARALLEL|COST|ROWS|SUPPORT|SET|WINDOW|LEAKPROOF|NOT|TRANSFORM|BEGIN)\b`),
+		functionBodyRegex:    regexp.MustCompile(`(?i)\bAS\s+(\$(?:[A-Za-z_][A-Za-z0-9_]*)?\$|')`),
+		dollarQuoteRegex:     regexp.MustCompile(`^\$(?:[A-Za-z_][A-Za-z0-9_]*)?\$`),
+
+		functionLanguageRegex:   regexp.MustCompile(`(?i)\bLANGUAGE\s+'?(\w+)`),
+		functionVolatilityRegex: regexp.MustCompile(`(?i)\b(IMMUTABLE|STABLE|VOLATILE)\b`),
+		functionParallelRegex:   regexp.MustCompile(`(?i)\bPARALLEL\s+(SAFE|RESTRICTED|UNSAFE)\b`),
+		functionStrictRegex:     regexp.MustCompile(`(?i)\bSTRICT\b|RETURNS\s+NULL\s+ON\s+NULL\s+INPUT`),
+		functionSecurityRegex:   regexp.MustCompile(`(?i)\bSECURITY\s+DEFINER\b`),
+		argumentDefaultRegex:    regexp.MustCompile(`(?is)\s+DEFAULT\s.*$|\s*=.*$`),
+
+		createTypeRegex:    regexp.MustCompile(`(?is)CREATE\s+TYPE\s+([^\s(]+)\s+AS\s+(ENUM\s*)?\(`),
+		domainRegex:        regexp.MustCompile(`(?is)CREATE\s+DOMAIN\s+([^\s(]+)\s+(?:AS\s+)?(.+?)(?:\s+((?:DEFAULT|NOT\s+NULL|NULL|CONSTRAINT|CHECK|COLLATE)\b.*))?$`),
+		domainCheckRegex:   regexp.MustCompile(`(?i)(?:CONSTRAINT\s+(\S+)\s+)?CHECK\s*\(`),
+		domainDefaultRegex: regexp.MustCompile(`(?is)\bDEFAULT\s+(.+?)(?:\s+(?:NOT\s+NULL|NULL|COLLATE)\b|\s*$)`),
+		domainNotNullRegex: regexp.MustCompile(`(?i)\bNOT\s+NULL\b`),
+		alterTypeRegex:     regexp.MustCompile(`(?is)ALTER\s+TYPE\s+(\S+)\s+ADD\s+VALUE\s+(?:IF\s+NOT\s+EXISTS\s+)?'((?:[^']|'')*)'(?:\s+(BEFORE|AFTER)\s+'((?:[^']|'')*)')?`),
+		quotedLiteralRegex: regexp.MustCompile(`'((?:[^']|'')*)'`),
+
+		extensionRegex:        regexp.MustCompile(`(?is)CREATE\s+EXTENSION\s+(?:IF\s+NOT\s+EXISTS\s+)?(\S+)(.*)$`),
+		extensionSchemaRegex:  regexp.MustCompile(`(?i)\bSCHEMA\s+(\S+)`),
+		extensionVersionRegex: regexp.MustCompile(`(?i)\bVERSION\s+(?:'((?:[^']|'')*)'|(\S+))`),
+
+		policyRegex:          regexp.MustCompile(`(?is)CREATE\s+POLICY\s+(\S+)\s+ON\s+(\S+)(.*)$`),
+		policyUsingRegex:     regexp.MustCompile(`(?i)\bUSING\s*\(`),
+		policyWithCheckRegex: regexp.MustCompile(`(?i)\bWITH\s+CHECK\s*\(`),
+		policyOptionsRegex:   regexp.MustCompile(`(?is)^\s*(?:AS\s+(PERMISSIVE|RESTRICTIVE)\s*)?(?:FOR\s+(ALL|SELECT|INSERT|UPDATE|DELETE)\s*)?(?:TO\s+(.+?))?\s*$`),
+		rowSecurityRegex:     regexp.MustCompile(`(?i)^(ENABLE|DISABLE|FORCE|NO\s+FORCE)\s+ROW\s+LEVEL\s+SECURITY`),
+
+		grantRegex:       regexp.MustCompile(`(?is)^GRANT\s+(.+?)\s+ON\s+(.+?)\s+TO\s+(.+?)(\s+WITH\s+GRANT\s+OPTION)?(?:\s+GRANTED\s+BY\s+\S+)?$`),
+		revokeRegex:      regexp.MustCompile(`(?is)^REVOKE\s+(GRANT\s+OPTION\s+FOR\s+)?(.+?)\s+ON\s+(.+?)\s+FROM\s+(.+?)(?:\s+GRANTED\s+BY\s+\S+)?(?:\s+(?:CASCADE|RESTRICT))?$`),
+		grantTargetRegex: regexp.MustCompile(`(?is)^(?:(TABLE|FOREIGN\s+TABLE|SEQUENCE|FUNCTION|PROCEDURE|ROUTINE|SCHEMA)\s+)?(.+)$`),
+		grantAllRegex:    regexp.MustCompile(`(?is)^ALL\s+(TABLES|SEQUENCES|FUNCTIONS|PROCEDURES|ROUTINES)\s+IN\s+SCHEMA\s+`),
+		ownerToRegex:     regexp.MustCompile(`(?is)^ALTER\s+(TABLE|VIEW|MATERIALIZED\s+VIEW|FOREIGN\s+TABLE|SEQUENCE|FUNCTION|PROCEDURE|ROUTINE|SCHEMA)\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?(.+?)\s+OWNER\s+TO\s+(\S+)$`),
+
+		partitionOfRegex:     regexp.MustCompile(`(?is)CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([^\s(]+)\s+PARTITION\s+OF\s+([^\s(]+)\s*(.*)$`),
+		partitionByRegex:     regexp.MustCompile(`(?is)PARTITION\s+BY\s+(RANGE|LIST|HASH)\s*\(`),
+		partitionBoundRegex:  regexp.MustCompile(`(?is)^(DEFAULT|FOR\s+VALUES\s+.+?)(?:\s+PARTITION\s+BY\s+.*)?$`),
+		attachPartitionRegex: regexp.MustCompile(`(?is)^ATTACH\s+PARTITION\s+(\S+)\s+(DEFAULT|FOR\s+VALUES\s+.+)$`),
+		detachPartitionRegex: regexp.MustCompile(`(?is)^DETACH\s+PARTITION\s+(\S+)`),
 	}
 }
 
@@ -91,7 +243,38 @@ func (p *SQLParser) splitStatements(content string) []string {
 
 	runes:= []rune(pureContent)
 
+	// dollar-quoted function bodies can contain semicolons and quotes of their own
+	var dollarTag string
+	var skip int
+
+	// SQLite trigger bodies are BEGIN ... END blocks of statements, which may nest CASE ... END
+	var blockDepth int
+
 	for i, r := range runes {
+		if skip > 0 {
+			current.WriteRune(r)
+			skip--
+			continue
+		}
+
+		if dollarTag != "" {
+			if r == '$' && strings.HasPrefix(string(runes[i:]), dollarTag) {
+				skip = len(dollarTag) - 1
+				dollarTag = ""
+			}
+			current.WriteRune(r)
+			continue
+		}
+
+		if !inString && r == '$' {
+			if tag := p.dollarQuoteRegex.FindString(string(runes[i:])); tag != "" {
+				dollarTag = tag
+				skip = len(tag) - 1
+				current.WriteRune(r)
+				continue
+			}
+		}
+
 		if !inString {
 			switch r {
 			case '\'' , '"':
@@ -104,15 +287,35 @@ func (p *SQLParser) splitStatements(content string) []string {
 			case ')':
 				parenDepth--
 			case ';':
-				if !inFunction || parenDepth == 0 {
+				if blockDepth == 0 && (!inFunction || parenDepth == 0) {
 					statements = append(statements, current.String())
 					current.Reset()
 					continue
 				}
 			}
 
+			if i == 0 || !isIdentifierRune(runes[i-1]) {
+				word := p.blockKeywordRegex.FindString(string(runes[i:min(i+len("BEGIN")+1, len(runes))]))
+				switch strings.ToUpper(word) {
+				case "BEGIN":
+					if blockDepth > 0 || p.triggerStartRegex.MatchString(current.String()) {
+						blockDepth++
+					}
+				case "CASE":
+					if blockDepth > 0 {
+						blockDepth++
+					}
+				case "END":
+					if blockDepth > 0 {
+						blockDepth--
+					}
+				}
+			}
+
 			if strings.HasPrefix(strings.ToUpper(string(runes[i:])), "CREATE FUNCTION") || 
-			strings.HasPrefix(strings.ToUpper(string(runes[i:])), "CREATE OR REPLACE FUNCTION") {
+			strings.HasPrefix(strings.ToUpper(string(runes[i:])), "CREATE OR REPLACE FUNCTION") ||
+			strings.HasPrefix(strings.ToUpper(string(runes[i:])), "CREATE PROCEDURE") ||
+			strings.HasPrefix(strings.ToUpper(string(runes[i:])), "CREATE OR REPLACE PROCEDURE") {
 				inFunction = true
 			} else if inFunction && r==';' && parenDepth == 0 {
 				inFunction = false
@@ -137,19 +340,47 @@ func (p *SQLParser) splitStatements(content string) []string {
 	return statements
 } 
 
+// isIdentifierRune reports whether r can be part of an unquoted identifier or keyword
+func isIdentifierRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
 func (p *SQLParser) parseStatements(schema *models.Schema, stmnt string) error {
 	stmnt = strings.TrimSpace(stmnt)
 	stmtUpper := strings.ToUpper(stmnt)
 
 	switch {
+	case p.ownerToRegex.MatchString(stmnt):
+		// ALTER ... OWNER TO applies to tables, sequences and functions alike
+		return p.parseOwnerTo(schema, stmnt)
+	case strings.HasPrefix(stmtUpper, "GRANT"):
+		return p.parseGrant(schema, stmnt)
+	case strings.HasPrefix(stmtUpper, "REVOKE"):
+		return p.parseRevoke(schema, stmnt)
 	case strings.HasPrefix(stmtUpper, "CREATE TABLE"):
 		return p.parseCreateTable(schema, stmnt)
 	case strings.HasPrefix(stmtUpper, "CREATE INDEX") || strings.HasPrefix(stmtUpper, "CREATE UNIQUE INDEX"):
 		return p.parseCreateIndex(schema, stmnt)
 	case strings.HasPrefix(stmtUpper, "CREATE SEQUENCE"):
 		return p.parseCreateSequence(schema, stmnt)
-	case strings.HasPrefix(stmtUpper, "CREATE FUNCTION") || strings.HasPrefix(stmtUpper, "CREATE OR REPLACE FUNCTION"):
+	case strings.HasPrefix(stmtUpper, "CREATE FUNCTION") || strings.HasPrefix(stmtUpper, "CREATE OR REPLACE FUNCTION") ||
+		strings.HasPrefix(stmtUpper, "CREATE PROCEDURE") || strings.HasPrefix(stmtUpper, "CREATE OR REPLACE PROCEDURE"):
 		return p.parseCreateFunction(schema, stmnt)
+	case strings.HasPrefix(stmtUpper, "CREATE TRIGGER") || strings.HasPrefix(stmtUpper, "CREATE OR REPLACE TRIGGER") ||
+		strings.HasPrefix(stmtUpper, "CREATE CONSTRAINT TRIGGER"):
+		return p.parseCreateTrigger(schema, stmnt)
+	case strings.HasPrefix(stmtUpper, "CREATE EXTENSION"):
+		return p.parseCreateExtension(schema, stmnt)
+	case strings.HasPrefix(stmtUpper, "CREATE POLICY"):
+		return p.parseCreatePolicy(schema, stmnt)
+	case strings.HasPrefix(stmtUpper, "CREATE TYPE"):
+		return p.parseCreateType(schema, stmnt)
+	case strings.HasPrefix(stmtUpper, "CREATE DOMAIN"):
+		return p.parseCreateDomain(schema, stmnt)
+	case strings.HasPrefix(stmtUpper, "ALTER TYPE"):
+		return p.parseAlterType(schema, stmnt)
+	case strings.HasPrefix(stmtUpper, "ALTER SEQUENCE"):
+		return p.parseAlterSequence(schema, stmnt)
 	case strings.HasPrefix(stmtUpper, "ALTER TABLE"):
 		return p.parseAlterTable(schema, stmnt)
 	default:
@@ -161,6 +392,10 @@ func (p *SQLParser) parseStatements(schema *models.Schema, stmnt string) error {
 }
 
 func (p *SQLParser) parseCreateTable(schema *models.Schema, stmnt string) error {
+	if p.partitionOfRegex.MatchString(stmnt) {
+		return p.parseCreatePartition(schema, stmnt)
+	}
+
 	matches:= p.createTableRegex.FindStringSubmatch(stmnt)
 
 	if len(matches) < 2 { 
@@ -183,18 +418,91 @@ func (p *SQLParser) parseCreateTable(schema *models.Schema, stmnt string) error
 
 	// Extract table definition (content between parentheses)
 	start := strings.Index(stmnt, "(")
-	end := strings.LastIndex(stmnt, ")")
+	end := p.closingParen(stmnt, start)
 	if start == -1 || end == -1 || start >= end {
 		return fmt.Errorf("invalid table definition")
 	}
 
 	definition:= stmnt[start+1: end]
 
+	if err := p.parsePartitionBy(table, stmnt[end+1:]); err != nil {
+		return err
+	}
+
 		// Parse columns and constraints
 		if err := p.parseTableDefinition(table, definition); err != nil {
 			return fmt.Errorf("failed to parse table definition: %w", err)
 		}
 
+	// primary key columns are implicitly NOT NULL
+	for _, constraint := range table.Constraints {
+		if constraint.Type != "PRIMARY KEY" {
+			continue
+		}
+		for _, column := range table.Columns {
+			if slices.Contains(constraint.Columns, column.Name) {
+				column.IsNullable = false
+			}
+		}
+	}
+
+	schema.Tables = append(schema.Tables, table)
+	return nil
+}
+
+// parsePartitionBy parses the PARTITION BY clause that may follow a table's definition
+func (p *SQLParser) parsePartitionBy(table *models.Table, tail string) error {
+	loc := p.partitionByRegex.FindStringSubmatchIndex(tail)
+	if loc == nil {
+		return nil
+	}
+
+	end := p.closingParen(tail, loc[1]-1)
+	if end == -1 {
+		return fmt.Errorf("unterminated partition key for table %s", table.Name)
+	}
+
+	table.PartitionStrategy = strings.ToUpper(tail[loc[2]:loc[3]])
+	table.PartitionKey = strings.TrimSpace(tail[loc[1]:end])
+	return nil
+}
+
+// parseCreatePartition parses CREATE TABLE ... PARTITION OF. Partitions inherit their columns
+// and constraints, so only the parent, the bound and any sub-partitioning are kept
+func (p *SQLParser) parseCreatePartition(schema *models.Schema, stmt string) error {
+	matches := p.partitionOfRegex.FindStringSubmatch(stmt)
+	if len(matches) < 4 {
+		return fmt.Errorf("invalid CREATE TABLE ... PARTITION OF statement")
+	}
+
+	table := &models.Table{
+		Name:        p.cleanIdentifier(matches[1]),
+		Schema:      schema.Name,
+		Columns:     make([]*models.Column, 0),
+		Constraints: make([]*models.Constraint, 0),
+		PartitionOf: p.cleanIdentifier(matches[2]),
+	}
+
+	// skip the optional list of column options and constraints
+	rest := strings.TrimSpace(matches[3])
+	if strings.HasPrefix(rest, "(") {
+		end := p.closingParen(rest, 0)
+		if end == -1 {
+			return fmt.Errorf("invalid definition for partition %s", table.Name)
+		}
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+
+	boundMatches := p.partitionBoundRegex.FindStringSubmatch(rest)
+	if len(boundMatches) < 2 {
+		return fmt.Errorf("missing bound for partition %s", table.Name)
+	}
+	table.PartitionBound = strings.Join(strings.Fields(boundMatches[1]), " ")
+
+	if err := p.parsePartitionBy(table, rest); err != nil {
+		return err
+	}
+
 	schema.Tables = append(schema.Tables, table)
 	return nil
 }
@@ -268,38 +576,111 @@ func (p *SQLParser) parseTablePart(part string, table *models.Table) error {
 		return p.parseTableConstraint(table, part)
 	}
 
+	// table level constraints without an explicit name
+	if strings.HasPrefix(partUpper, "PRIMARY KEY") || strings.HasPrefix(partUpper, "FOREIGN KEY") ||
+		strings.HasPrefix(partUpper, "UNIQUE") || strings.HasPrefix(partUpper, "CHECK") ||
+		strings.HasPrefix(partUpper, "EXCLUDE") {
+		return p.parseUnnamedConstraint(table, part)
+	}
+
+	// anything else is a column, which may carry inline constraints
+	if err := p.parseColumnDefinition(table, part); err != nil {
+		return err
+	}
 
 	if strings.Contains(partUpper, "PRIMARY KEY") {
 		return p.parseInlinePrimaryKey(table, part)
 	}
 
-	if strings.Contains(partUpper, "FOREIGN KEY") {
+	if strings.Contains(partUpper, "REFERENCES") {
 		return p.parseInlineForeignKey(table, part)
 	}
 
-	if strings.Contains(partUpper, "UNIQUE") && !strings.Contains(partUpper, "NOT") {
+	if strings.Contains(partUpper, "UNIQUE") {
 		return p.parseInlineUnique(table, part)
 	}
 
+	if idx := strings.Index(partUpper, "CHECK"); idx != -1 {
+		return p.parseInlineCheck(table, part, part[idx+len("CHECK"):])
+	}
 
-	return p.parseColumnDefinition(table, part)
+	return nil
 }
 
-func (p *SQLParser) parseColumnDefinition(table *models.Table, definition string) error {
-	parts:= strings.Fields(definition)
+// parseUnnamedConstraint parses a table level constraint that has no CONSTRAINT clause,
+// naming it the way PostgreSQL would
+func (p *SQLParser) parseUnnamedConstraint(table *models.Table, definition string) error {
+	if err := p.parseTableConstraint(table, "CONSTRAINT unnamed "+definition); err != nil {
+		return err
+	}
+
+	constraint := table.Constraints[len(table.Constraints)-1]
+	columns := strings.Join(constraint.Columns, "_")
+
+	switch constraint.Type {
+	case models.PRIMARY_KEY:
+		constraint.Name = fmt.Sprintf("%s_pkey", table.Name)
+	case models.FOREIGN_KEY:
+		constraint.Name = fmt.Sprintf("%s_%s_fkey", table.Name, columns)
+	case models.UNIQUE:
+		constraint.Name = fmt.Sprintf("%s_%s_key", table.Name, columns)
+	case models.EXCLUDE:
+		constraint.Name = fmt.Sprintf("%s_excl", table.Name)
+	default:
+		constraint.Name = fmt.Sprintf("%s_check", table.Name)
+	}
+
+	return nil
+}
 
-	if len(parts) < 2 {
+func (p *SQLParser) parseColumnDefinition(table *models.Table, definition string) error {
+	typeMatches := p.columnTypeRegex.FindStringSubmatch(definition)
+	if len(typeMatches) < 3 {
 		return fmt.Errorf("invalid column definition")
 	}
-	columnName:= p.cleanIdentifier(parts[0])
-	columnType:= parts[1]
+	columnName:= p.cleanIdentifier(typeMatches[1])
+	columnType:= strings.Join(strings.Fields(typeMatches[2]), " ")
 
 	column:= &models.Column{
 		Name: columnName,
-		DataType: columnType,
 		IsNullable: true,
 		DefaultValue: "",
 	}
+	column.SetDataType(columnType)
+
+	if collateMatches := p.collateRegex.FindStringSubmatch(definition); len(collateMatches) > 1 {
+		// pg_dump qualifies the built-in collations, e.g. pg_catalog."C"
+		if collation := p.cleanIdentifier(strings.TrimPrefix(collateMatches[1], "pg_catalog.")); collation != "default" {
+			column.Collation = collation
+		}
+	}
+	// identity columns are implicitly NOT NULL. The clause is removed so its
+	// BY DEFAULT isn't mistaken for a column default
+	if identityMatches := p.identityRegex.FindStringSubmatch(definition); len(identityMatches) > 1 {
+		column.Identity = strings.ToUpper(strings.Join(strings.Fields(identityMatches[1]), " "))
+		column.IsNullable = false
+		definition = p.identityRegex.ReplaceAllString(definition, "")
+	}
+
+	// the generation expression is removed for the same reason, as it may contain DEFAULT or NOT NULL
+	if loc := p.generatedClause(definition, len(typeMatches[0])); loc != nil {
+		end := p.closingParen(definition, loc[1]-1)
+		if end == -1 {
+			return fmt.Errorf("unterminated generation expression in column %s", columnName)
+		}
+		column.Generated = strings.TrimSpace(definition[loc[1]:end])
+		rest := strings.TrimSpace(definition[end+1:])
+		// PostgreSQL before 18 requires STORED, while MySQL, SQLite and PostgreSQL 18 default to VIRTUAL
+		column.Virtual = true
+		for _, kind := range []string{"STORED", "VIRTUAL"} {
+			if len(rest) >= len(kind) && strings.EqualFold(rest[:len(kind)], kind) {
+				column.Virtual = kind == "VIRTUAL"
+				rest = rest[len(kind):]
+			}
+		}
+		definition = definition[:loc[0]] + " " + rest
+	}
+
 	definitionUpper := strings.ToUpper(definition)
 
 	if strings.Contains(definitionUpper, "NOT NULL") {
@@ -307,10 +688,9 @@ func (p *SQLParser) parseColumnDefinition(table *models.Table, definition string
 	}
 
 	if strings.Contains(definitionUpper, "DEFAULT") {
-		defaultRegex := regexp.MustCompile(`(?i)DEFAULT\s+([^,\s]+(?:\([^)]*\))?)`)
-		matches := defaultRegex.FindStringSubmatch(definition)
+		matches := p.defaultRegex.FindStringSubmatch(definition)
 		if len(matches) > 1 {
-			column.DefaultValue = strings.Trim(matches[1], "'\"")
+			column.DefaultValue = matches[1]
 		}
 	}
 
@@ -318,6 +698,21 @@ func (p *SQLParser) parseColumnDefinition(table *models.Table, definition string
 	return nil
 }
 
+// generatedClause locates the GENERATED ALWAYS AS ( or bare AS ( that starts a generation
+// expression, ignoring any that appear inside string literals
+func (p *SQLParser) generatedClause(definition string, typeEnd int) []int {
+	if loc := p.bareGeneratedRegex.FindStringIndex(definition[typeEnd:]); loc != nil {
+		return []int{typeEnd + loc[0], typeEnd + loc[1]}
+	}
+
+	for _, loc := range p.generatedRegex.FindAllStringIndex(definition, -1) {
+		if definition[loc[0]] != '\'' {
+			return loc
+		}
+	}
+	return nil
+}
+
 func (p *SQLParser) parseTableConstraint(table *models.Table, definition string) error {
 	matches := p.constraintRegex.FindStringSubmatch(definition)
 	if len(matches) < 3 {
@@ -348,10 +743,10 @@ func (p *SQLParser) parseTableConstraint(table *models.Table, definition string)
 				constraint.References = p.cleanIdentifier(matches[2])
 			}
 			if len(matches) > 3 && matches[3] != "" {
-				constraint.References= matches[3]
-				// p.parseColumnList(matches[3])
+				constraint.References = fmt.Sprintf("%s(%s)", constraint.References, strings.Join(p.parseColumnList(matches[3]), ", "))
 			}
 		}
+		p.parseReferentialActions(constraint, constraintDef)
 	case strings.HasPrefix(constraintDefUpper, "UNIQUE"):
 		constraint.Type = "UNIQUE"
 		if matches := p.uniqueRegex.FindStringSubmatch(constraintDef); len(matches) > 1 {
@@ -359,7 +754,11 @@ func (p *SQLParser) parseTableConstraint(table *models.Table, definition string)
 		}
 	case strings.HasPrefix(constraintDefUpper, "CHECK"):
 		constraint.Type = "CHECK"
-		
+		constraint.CheckExpr = p.extractParenthesized(constraintDef[len("CHECK"):])
+	case strings.HasPrefix(constraintDefUpper, "EXCLUDE"):
+		constraint.Type = "EXCLUDE"
+		constraint.Definition = constraintDef
+
 	default:
 		constraint.Type = "OTHER"
 	}
@@ -401,7 +800,7 @@ func (p *SQLParser) parseInlineForeignKey(table *models.Table, definition string
 	columnName := p.cleanIdentifier(parts[0])
 	constraintName := fmt.Sprintf("%s_%s_fkey", table.Name, columnName)
 	
-	matches := p.foreignKeyRegex.FindStringSubmatch(definition)
+	matches := p.referencesRegex.FindStringSubmatch(definition)
 	constraint := &models.Constraint{
 		Name:       constraintName,
 		Type:       "FOREIGN KEY",
@@ -409,19 +808,77 @@ func (p *SQLParser) parseInlineForeignKey(table *models.Table, definition string
 		RawSQL: definition,
 	}
 	
-	if len(matches) > 2 {
-		// constraint.References = p.cleanIdentifier(matches[2])
-		constraint.References = matches[2]
-		if len(matches) > 3 && matches[3] != "" {
-			constraint.Columns = p.parseColumnList(matches[3])
+	if len(matches) > 1 {
+		constraint.References = p.cleanIdentifier(matches[1])
+		if len(matches) > 2 && matches[2] != "" {
+			constraint.References = fmt.Sprintf("%s(%s)", constraint.References, strings.Join(p.parseColumnList(matches[2]), ", "))
 		}
 	}
+	p.parseReferentialActions(constraint, definition)
 
 	
 	table.Constraints = append(table.Constraints, constraint)
 	return nil
 }
 
+// parseReferentialActions reads ON DELETE / ON UPDATE actions and deferrability of a foreign key
+func (p *SQLParser) parseReferentialActions(constraint *models.Constraint, definition string) {
+	if matches := p.onDeleteRegex.FindStringSubmatch(definition); len(matches) > 1 {
+		constraint.OnDelete = p.normalizeAction(matches[1])
+	}
+	if matches := p.onUpdateRegex.FindStringSubmatch(definition); len(matches) > 1 {
+		constraint.OnUpdate = p.normalizeAction(matches[1])
+	}
+
+	definitionUpper := strings.ToUpper(definition)
+	constraint.Deferrable = strings.Contains(definitionUpper, "DEFERRABLE") && !strings.Contains(definitionUpper, "NOT DEFERRABLE")
+	constraint.InitiallyDeferred = strings.Contains(definitionUpper, "INITIALLY DEFERRED")
+}
+
+// normalizeAction upper-cases a referential action, mapping the default NO ACTION to an empty string
+func (p *SQLParser) normalizeAction(action string) string {
+	action = strings.ToUpper(strings.Join(strings.Fields(action), " "))
+	if action == "NO ACTION" {
+		return ""
+	}
+	return action
+}
+
+// extractParenthesized returns the content of the first balanced parenthesized group in s
+func (p *SQLParser) extractParenthesized(s string) string {
+	start := strings.Index(s, "(")
+	if start == -1 {
+		return strings.TrimSpace(s)
+	}
+
+	end := p.closingParen(s, start)
+	if end == -1 {
+		return strings.TrimSpace(s[start+1:])
+	}
+
+	return strings.TrimSpace(s[start+1 : end])
+}
+
+// parseInlineCheck parses an inline column CHECK constraint
+func (p *SQLParser) parseInlineCheck(table *models.Table, definition, expr string) error {
+	parts := strings.Fields(definition)
+	if len(parts) == 0 {
+		return fmt.Errorf("invalid check constraint definition")
+	}
+
+	columnName := p.cleanIdentifier(parts[0])
+
+	constraint := &models.Constraint{
+		Name:      fmt.Sprintf("%s_%s_check", table.Name, columnName),
+		Type:      "CHECK",
+		Columns:   []string{columnName},
+		CheckExpr: p.extractParenthesized(expr),
+	}
+
+	table.Constraints = append(table.Constraints, constraint)
+	return nil
+}
+
 // parseInlineUnique parses an inline UNIQUE constraint
 func (p *SQLParser) parseInlineUnique(table *models.Table, definition string) error {
 	parts := strings.Fields(definition)
@@ -445,10 +902,11 @@ func (p *SQLParser) parseInlineUnique(table *models.Table, definition string) er
 
 // parseCreateIndex parses a CREATE INDEX statement
 func (p *SQLParser) parseCreateIndex(schema *models.Schema, stmt string) error {
-	matches := p.indexRegex.FindStringSubmatch(stmt)
-	if len(matches) < 6 {
+	loc := p.indexRegex.FindStringSubmatchIndex(stmt)
+	if loc == nil {
 		return fmt.Errorf("invalid CREATE INDEX statement")
 	}
+	matches := p.indexRegex.FindStringSubmatch(stmt)
 
 	isUnique := matches[1] != ""
 	indexName := p.cleanIdentifier(matches[2])
@@ -457,21 +915,167 @@ func (p *SQLParser) parseCreateIndex(schema *models.Schema, stmt string) error {
 	if matches[4] != "" {
 		method = strings.ToLower(matches[4])
 	}
-	columns := p.parseColumnList(matches[5])
+
+	// the regex stops at the opening parenthesis of the key list
+	keyStart := loc[1] - 1
+	keyEnd := p.closingParen(stmt, keyStart)
+	if keyEnd == -1 {
+		return fmt.Errorf("invalid CREATE INDEX statement: unbalanced key list")
+	}
 
 	index := &models.Index{
 		Name:     indexName,
 		Table:    tableName,
-		Columns:  columns,
 		IsUnique: isUnique,
+		IsValid:  true,
 		Method:   method,
 	}
 
+	for _, key := range p.splitTableParts(stmt[keyStart+1 : keyEnd]) {
+		column, opClass, order := p.parseIndexKey(key)
+		index.Columns = append(index.Columns, column)
+		index.OpClasses = append(index.OpClasses, opClass)
+		index.SortOrders = append(index.SortOrders, order)
+	}
+
+	rest := stmt[keyEnd+1:]
+	if idx := strings.Index(strings.ToUpper(rest), "INCLUDE"); idx != -1 {
+		index.Include = p.parseColumnList(p.extractParenthesized(rest[idx+len("INCLUDE"):]))
+	}
+	if idx := strings.Index(strings.ToUpper(rest), "WHERE"); idx != -1 {
+		index.Where = strings.TrimSpace(rest[idx+len("WHERE"):])
+	}
+
 	schema.Indexes = append(schema.Indexes, index)
 
 	return nil
 }
 
+// parseIndexKey splits an index key into its column or expression, non-default operator class
+// and non-default sort order, using the same conventions as the PostgreSQL extractor
+func (p *SQLParser) parseIndexKey(key string) (column, opClass, order string) {
+	fields := strings.Fields(strings.TrimSpace(key))
+
+	var desc, nullsFirst, nullsLast bool
+	for len(fields) > 0 {
+		last := strings.ToUpper(fields[len(fields)-1])
+		if len(fields) >= 2 && strings.ToUpper(fields[len(fields)-2]) == "NULLS" {
+			nullsFirst = last == "FIRST"
+			nullsLast = last == "LAST"
+			fields = fields[:len(fields)-2]
+			continue
+		}
+		if last == "ASC" || last == "DESC" {
+			desc = last == "DESC"
+			fields = fields[:len(fields)-1]
+			continue
+		}
+		break
+	}
+
+	if len(fields) > 1 && strings.HasSuffix(strings.ToLower(fields[len(fields)-1]), "_ops") {
+		opClass = strings.ToLower(fields[len(fields)-1])
+		fields = fields[:len(fields)-1]
+	}
+
+	column = strings.Join(fields, " ")
+	if strings.HasPrefix(column, "(") && p.closingParen(column, 0) == len(column)-1 {
+		column = strings.TrimSpace(column[1 : len(column)-1])
+	} else {
+		column = p.cleanIdentifier(column)
+	}
+
+	switch {
+	case desc && nullsLast:
+		order = "DESC NULLS LAST"
+	case desc:
+		order = "DESC"
+	case nullsFirst:
+		order = "NULLS FIRST"
+	}
+
+	return column, opClass, order
+}
+
+// closingParen returns the index of the parenthesis closing the one at start, or -1
+func (p *SQLParser) closingParen(s string, start int) int {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseCreateTrigger parses a CREATE TRIGGER statement
+func (p *SQLParser) parseCreateTrigger(schema *models.Schema, stmt string) error {
+	matches := p.triggerRegex.FindStringSubmatch(stmt)
+	if len(matches) < 6 {
+		return fmt.Errorf("invalid CREATE TRIGGER statement")
+	}
+
+	trigger := &models.Trigger{
+		Name:    p.cleanIdentifier(matches[1]),
+		Timing:  "BEFORE",
+		Table:   p.cleanIdentifier(matches[4]),
+		ForEach: "STATEMENT",
+		State:   "ENABLED",
+	}
+	// only SQLite lets the timing be left out
+	if matches[2] != "" {
+		trigger.Timing = strings.ToUpper(strings.Join(strings.Fields(matches[2]), " "))
+	}
+
+	for _, event := range p.triggerEventsRegex.Split(strings.TrimSpace(matches[3]), -1) {
+		fields := strings.Fields(event)
+		if len(fields) > 2 && strings.EqualFold(fields[1], "OF") {
+			columns := strings.Join(fields[2:], " ")
+			event = "UPDATE OF " + strings.Join(p.parseColumnList(columns), ", ")
+		} else {
+			event = strings.ToUpper(event)
+		}
+		trigger.Events = append(trigger.Events, event)
+	}
+
+	rest := matches[5]
+
+	// a SQLite trigger runs a BEGIN ... END block of statements for each row, and its WHEN
+	// condition needn't be parenthesized
+	if loc := p.triggerBodyRegex.FindStringSubmatchIndex(rest); loc != nil {
+		trigger.ForEach = "ROW"
+		trigger.Statement = strings.TrimSuffix(rest[loc[2]:loc[3]], ";")
+		rest = rest[:loc[0]]
+		if idx := strings.Index(strings.ToUpper(rest), "WHEN"); idx != -1 {
+			trigger.When = strings.TrimSpace(rest[idx+len("WHEN"):])
+		}
+
+		schema.Triggers = append(schema.Triggers, trigger)
+		return nil
+	}
+
+	if forEach := p.triggerForEachRegex.FindStringSubmatch(rest); len(forEach) > 1 {
+		trigger.ForEach = strings.ToUpper(forEach[1])
+	}
+
+	if idx := strings.Index(strings.ToUpper(rest), "WHEN"); idx != -1 {
+		trigger.When = p.extractParenthesized(rest[idx+len("WHEN"):])
+	}
+
+	if execute := p.triggerExecuteRegex.FindStringSubmatch(rest); len(execute) > 1 {
+		trigger.Statement = strings.TrimSpace(execute[1])
+	}
+
+	schema.Triggers = append(schema.Triggers, trigger)
+	return nil
+}
+
 // parseCreateSequence parses a CREATE SEQUENCE statement
 func (p *SQLParser) parseCreateSequence(schema *models.Schema, stmt string) error {
 	matches := p.sequenceRegex.FindStringSubmatch(stmt)
@@ -480,60 +1084,689 @@ func (p *SQLParser) parseCreateSequence(schema *models.Schema, stmt string) erro
 	}
 
 	sequenceName := p.cleanIdentifier(matches[1])
-	sequence := &models.Sequence{
-		Name:       sequenceName,
-		Start: 1,
-		Increment:  1,
-	}
+	sequence := models.NewSequence(schema.Name, sequenceName, "bigint")
 
 	// Parse sequence options if present
-	if len(matches) > 2 && matches[2] != "" {
-		options := strings.ToUpper(matches[2])
-		
-		startRegex := regexp.MustCompile(`START\s+WITH\s+(\d+)`)
-		if startMatches := startRegex.FindStringSubmatch(options); len(startMatches) > 1 {
-			if val, err := strconv.ParseInt(startMatches[1], 10, 64); err == nil {
-				sequence.Start = val
-			}
-		}
-		
-		incRegex := regexp.MustCompile(`INCREMENT\s+BY\s+(\d+)`)
-		if incMatches := incRegex.FindStringSubmatch(options); len(incMatches) > 1 {
-			if val, err := strconv.ParseInt(incMatches[1], 10, 64); err == nil {
-				sequence.Increment = val
-			}
-		}
+	options := ""
+	if len(matches) > 2 {
+		options = matches[2]
+	}
+
+	if typeMatches := p.sequenceTypeRegex.FindStringSubmatch(options); len(typeMatches) > 1 {
+		sequence.SetDataType(typeMatches[1])
+	}
+
+	if val, ok := p.sequenceOption(options, p.sequenceIncrementRegex); ok {
+		sequence.Increment = val
+	}
+
+	// descending sequences count down from -1 by default
+	if sequence.Increment < 0 {
+		sequence.Min, sequence.Max = -sequence.Max-1, -1
+	}
+
+	if val, ok := p.sequenceOption(options, p.sequenceMinRegex); ok {
+		sequence.Min = val
+	}
+	if val, ok := p.sequenceOption(options, p.sequenceMaxRegex); ok {
+		sequence.Max = val
+	}
+
+	sequence.Start = sequence.Min
+	if sequence.Increment < 0 {
+		sequence.Start = sequence.Max
+	}
+	if val, ok := p.sequenceOption(options, p.sequenceStartRegex); ok {
+		sequence.Start = val
+	}
+
+	if val, ok := p.sequenceOption(options, p.sequenceCacheRegex); ok {
+		sequence.Cache = val
+	}
+
+	sequence.Cycle = p.sequenceCycleRegex.MatchString(options) && !p.sequenceNoCycleRegex.MatchString(options)
+
+	if ownerMatches := p.sequenceOwnedByRegex.FindStringSubmatch(options); len(ownerMatches) > 1 {
+		sequence.OwnedBy = p.sequenceOwner(ownerMatches[1])
 	}
 
 	schema.Sequences = append(schema.Sequences, sequence)
 	return nil
 }
 
-// parseCreateFunction parses a CREATE FUNCTION statement
-func (p *SQLParser) parseCreateFunction(schema *models.Schema, stmt string) error {
-	matches := p.functionRegex.FindStringSubmatch(stmt)
+// sequenceOption returns the signed integer captured by optionRegex, e.g. -1 from "INCREMENT BY -1"
+func (p *SQLParser) sequenceOption(options string, optionRegex *regexp.Regexp) (int64, bool) {
+	matches := optionRegex.FindStringSubmatch(options)
+	if len(matches) < 2 {
+		return 0, false
+	}
+
+	val, err := strconv.ParseInt(matches[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return val, true
+}
+
+// sequenceOwner cleans up an OWNED BY target, returning "" for OWNED BY NONE
+func (p *SQLParser) sequenceOwner(owner string) string {
+	if strings.EqualFold(owner, "NONE") {
+		return ""
+	}
+
+	parts := strings.Split(owner, ".")
+	for i, part := range parts {
+		parts[i] = p.cleanIdentifier(part)
+	}
+	// keep table.column, dropping any schema qualification
+	if len(parts) > 2 {
+		parts = parts[len(parts)-2:]
+	}
+	return strings.Join(parts, ".")
+}
+
+// parseAlterSequence parses the OWNED BY clause of an ALTER SEQUENCE statement
+func (p *SQLParser) parseAlterSequence(schema *models.Schema, stmt string) error {
+	matches := p.alterSequenceRegex.FindStringSubmatch(stmt)
 	if len(matches) < 3 {
+		// other sequence options aren't tracked
+		return nil
+	}
+
+	sequenceName := p.cleanIdentifier(matches[1])
+	for _, seq := range schema.Sequences {
+		if seq.Name == sequenceName {
+			seq.OwnedBy = p.sequenceOwner(matches[2])
+			return nil
+		}
+	}
+
+	return fmt.Errorf("sequence %s not found for ALTER SEQUENCE", sequenceName)
+}
+
+// parseCreateFunction parses a CREATE FUNCTION or CREATE PROCEDURE statement
+func (p *SQLParser) parseCreateFunction(schema *models.Schema, stmt string) error {
+	loc := p.functionRegex.FindStringSubmatchIndex(stmt)
+	if loc == nil {
 		return fmt.Errorf("invalid CREATE FUNCTION statement")
 	}
 
-	functionName := p.cleanIdentifier(matches[1])
-	returnType := matches[2]
-	language := "sql" // default
-	if len(matches) > 3 && matches[3] != "" {
-		language = strings.ToLower(matches[3])
+	kind := strings.ToUpper(stmt[loc[2]:loc[3]])
+	functionName := p.cleanIdentifier(stmt[loc[4]:loc[5]])
+
+	argsEnd := p.closingParen(stmt, loc[1]-1)
+	if argsEnd == -1 {
+		return fmt.Errorf("invalid argument list for %s %s", strings.ToLower(kind), functionName)
 	}
 
 	function := &models.Function{
 		Name:       functionName,
-		ReturnType: returnType,
-		Language:   language,
+		Schema:     schema.Name,
+		Kind:       kind,
+		Arguments:  p.parseFunctionArguments(stmt[loc[1]:argsEnd]),
+		Language:   "sql", // default
+		Volatility: "VOLATILE",
+		Parallel:   "UNSAFE",
 		Definition: stmt, // Store the full definition for comparison
 	}
 
+	// the body is cut out so its contents aren't mistaken for attributes
+	rest := stmt[argsEnd+1:]
+	if bodyLoc := p.functionBodyRegex.FindStringSubmatchIndex(rest); bodyLoc != nil {
+		quote := rest[bodyLoc[2]:bodyLoc[3]]
+		if end := strings.Index(rest[bodyLoc[1]:], quote); end != -1 {
+			function.Body = rest[bodyLoc[1] : bodyLoc[1]+end]
+			if quote == "'" {
+				function.Body = strings.ReplaceAll(function.Body, "''", "'")
+			}
+			rest = rest[:bodyLoc[0]] + rest[bodyLoc[1]+end+len(quote):]
+		}
+	}
+
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(rest)), "RETURNS TABLE") {
+		start := strings.Index(rest, "(")
+		if end := p.closingParen(rest, start); start != -1 && end != -1 {
+			function.ReturnType = "TABLE(" + strings.Join(strings.Fields(rest[start+1:end]), " ") + ")"
+		}
+	} else if matches := p.functionReturnsRegex.FindStringSubmatch(rest + " AS"); len(matches) > 1 {
+		function.ReturnType = strings.TrimSpace(matches[1])
+	}
+
+	if matches := p.functionLanguageRegex.FindStringSubmatch(rest); len(matches) > 1 {
+		function.Language = strings.ToLower(matches[1])
+	}
+
+	if matches := p.functionVolatilityRegex.FindStringSubmatch(rest); len(matches) > 1 {
+		function.Volatility = strings.ToUpper(matches[1])
+	}
+
+	if matches := p.functionParallelRegex.FindStringSubmatch(rest); len(matches) > 1 {
+		function.Parallel = strings.ToUpper(matches[1])
+	}
+
+	function.Strict = p.functionStrictRegex.MatchString(rest)
+	function.SecurityDefiner = p.functionSecurityRegex.MatchString(rest)
+
 	schema.Functions = append(schema.Functions, function)
 	return nil
 }
 
+// parseFunctionArguments reduces an argument list to the identity arguments PostgreSQL
+// reports: defaults are dropped, as is the implicit IN mode
+func (p *SQLParser) parseFunctionArguments(args string) string {
+	var identity []string
+	for _, arg := range p.splitTableParts(args) {
+		arg = p.argumentDefaultRegex.ReplaceAllString(strings.TrimSpace(arg), "")
+		arg = strings.Join(strings.Fields(arg), " ")
+		if arg == "" {
+			continue
+		}
+
+		if strings.HasPrefix(strings.ToUpper(arg), "IN ") {
+			arg = arg[len("IN "):]
+		}
+		identity = append(identity, arg)
+	}
+
+	return strings.Join(identity, ", ")
+}
+
+// allPrivileges lists what GRANT ALL grants on each kind of object
+var allPrivileges = map[string][]string{
+	"TABLE":     {"SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER"},
+	"SEQUENCE":  {"USAGE", "SELECT", "UPDATE"},
+	"FUNCTION":  {"EXECUTE"},
+	"PROCEDURE": {"EXECUTE"},
+	"SCHEMA":    {"USAGE", "CREATE"},
+}
+
+// grantTarget is an object named in the ON clause of a GRANT or REVOKE
+type grantTarget struct {
+	objectType string
+	object     string
+}
+
+// grantTargets resolves the ON clause of a GRANT or REVOKE to the objects it names. ALL ... IN
+// SCHEMA expands to the matching objects parsed so far. Objects that aren't tracked, such as
+// databases and types, resolve to no targets
+func (p *SQLParser) grantTargets(schema *models.Schema, target string) []grantTarget {
+	target = strings.TrimSpace(target)
+	var targets []grantTarget
+
+	if loc := p.grantAllRegex.FindStringSubmatchIndex(target); loc != nil {
+		switch kind := strings.ToUpper(target[loc[2]:loc[3]]); kind {
+		case "TABLES":
+			for _, table := range schema.Tables {
+				targets = append(targets, grantTarget{"TABLE", table.Name})
+			}
+			for _, view := range schema.Views {
+				targets = append(targets, grantTarget{"TABLE", view.Name})
+			}
+		case "SEQUENCES":
+			for _, seq := range schema.Sequences {
+				targets = append(targets, grantTarget{"SEQUENCE", seq.Name})
+			}
+		default:
+			for _, function := range schema.Functions {
+				if kind == "ROUTINES" || strings.TrimSuffix(kind, "S") == function.Kind {
+					targets = append(targets, grantTarget{function.Kind, function.Signature()})
+				}
+			}
+		}
+		return targets
+	}
+
+	matches := p.grantTargetRegex.FindStringSubmatch(target)
+	objectType := strings.Join(strings.Fields(strings.ToUpper(matches[1])), " ")
+	switch objectType {
+	case "":
+		// TABLE is optional, but other kinds of object aren't
+		if first := strings.ToUpper(strings.Fields(target)[0]); first == "DATABASE" || first == "DOMAIN" ||
+			first == "FOREIGN" || first == "LANGUAGE" || first == "LARGE" || first == "PARAMETER" ||
+			first == "TABLESPACE" || first == "TYPE" {
+			return nil
+		}
+		objectType = "TABLE"
+	case "FOREIGN TABLE":
+		objectType = "TABLE"
+	}
+
+	for _, object := range p.splitTableParts(matches[2]) {
+		targets = append(targets, p.grantObject(schema, objectType, object))
+	}
+	return targets
+}
+
+// grantObject names an object the way models.Privilege and models.Owner identify it. Routines
+// are typed by what was created under their signature, as ROUTINE can name either kind
+func (p *SQLParser) grantObject(schema *models.Schema, objectType, object string) grantTarget {
+	object = strings.TrimSpace(object)
+	if objectType != "FUNCTION" && objectType != "PROCEDURE" && objectType != "ROUTINE" {
+		return grantTarget{objectType, p.cleanIdentifier(object)}
+	}
+
+	// a routine without an argument list is only allowed when it isn't overloaded
+	name := p.cleanIdentifier(object)
+	if open := strings.Index(object, "("); open != -1 {
+		end := p.closingParen(object, open)
+		if end == -1 {
+			end = len(object)
+		}
+		name = fmt.Sprintf("%s(%s)", p.cleanIdentifier(object[:open]), p.parseFunctionArguments(object[open+1:end]))
+	}
+
+	if objectType == "ROUTINE" {
+		objectType = "FUNCTION"
+	}
+	for _, function := range schema.Functions {
+		if function.Signature() == name && function.Kind != "" {
+			objectType = function.Kind
+		}
+	}
+	return grantTarget{objectType, name}
+}
+
+// grantPrivileges expands a GRANT or REVOKE privilege list. Column privileges are skipped as
+// they aren't tracked
+func (p *SQLParser) grantPrivileges(objectType, list string) []string {
+	var privileges []string
+	for _, privilege := range p.splitTableParts(list) {
+		privilege = strings.Join(strings.Fields(strings.ToUpper(privilege)), " ")
+		switch {
+		case privilege == "ALL" || privilege == "ALL PRIVILEGES":
+			privileges = append(privileges, allPrivileges[objectType]...)
+		case strings.Contains(privilege, "("):
+			continue
+		case privilege != "":
+			privileges = append(privileges, privilege)
+		}
+	}
+	return privileges
+}
+
+// grantees parses the role list of a GRANT or REVOKE
+func (p *SQLParser) grantees(list string) []string {
+	var roles []string
+	for _, role := range p.parseColumnList(list) {
+		role = strings.TrimPrefix(role, "GROUP ")
+		if strings.EqualFold(role, "PUBLIC") {
+			role = "public"
+		}
+		roles = append(roles, p.cleanIdentifier(role))
+	}
+	return roles
+}
+
+// parseGrant parses GRANT ... ON ... TO. Granting roles to roles isn't tracked
+func (p *SQLParser) parseGrant(schema *models.Schema, stmt string) error {
+	matches := p.grantRegex.FindStringSubmatch(stmt)
+	if len(matches) < 5 {
+		return nil
+	}
+
+	grantable := matches[4] != ""
+
+	for _, target := range p.grantTargets(schema, matches[2]) {
+		for _, privilege := range p.grantPrivileges(target.objectType, matches[1]) {
+			for _, grantee := range p.grantees(matches[3]) {
+				i := slices.IndexFunc(schema.Privileges, func(priv *models.Privilege) bool {
+					return priv.ObjectType == target.objectType && priv.Object == target.object &&
+						priv.Privilege == privilege && priv.Grantee == grantee
+				})
+				if i != -1 && schema.Privileges[i].Revoked {
+					// granting a revoked default restores it, which only needs listing with the grant option
+					if grantable {
+						schema.Privileges[i].Revoked = false
+						schema.Privileges[i].Grantable = true
+					} else {
+						schema.Privileges = slices.Delete(schema.Privileges, i, i+1)
+					}
+					continue
+				}
+				if i != -1 {
+					schema.Privileges[i].Grantable = schema.Privileges[i].Grantable || grantable
+					continue
+				}
+
+				schema.Privileges = append(schema.Privileges, &models.Privilege{
+					ObjectType: target.objectType,
+					Object:     target.object,
+					Grantee:    grantee,
+					Privilege:  privilege,
+					Grantable:  grantable,
+				})
+			}
+		}
+	}
+
+	return nil
+}
+
+// defaultPrivilege reports whether grantee holds privilege on target without being granted it:
+// the owner holds every privilege on its objects, and PUBLIC may execute routines
+func defaultPrivilege(schema *models.Schema, target grantTarget, grantee, privilege string) bool {
+	if grantee == "public" {
+		return privilege == "EXECUTE" && (target.objectType == "FUNCTION" || target.objectType == "PROCEDURE")
+	}
+
+	i := slices.IndexFunc(schema.Owners, func(o *models.Owner) bool {
+		return o.ObjectType == target.objectType && o.Object == target.object
+	})
+	return i != -1 && schema.Owners[i].Owner == grantee
+}
+
+// parseRevoke parses REVOKE ... ON ... FROM, removing privileges granted earlier in the file.
+// Revoking a privilege the grantee holds by default is recorded as a revoked privilege
+func (p *SQLParser) parseRevoke(schema *models.Schema, stmt string) error {
+	matches := p.revokeRegex.FindStringSubmatch(stmt)
+	if len(matches) < 5 {
+		return nil
+	}
+
+	grantOptionOnly := matches[1] != ""
+	grantees := p.grantees(matches[4])
+
+	for _, target := range p.grantTargets(schema, matches[3]) {
+		for _, privilege := range p.grantPrivileges(target.objectType, matches[2]) {
+			for _, grantee := range grantees {
+				i := slices.IndexFunc(schema.Privileges, func(priv *models.Privilege) bool {
+					return priv.ObjectType == target.objectType && priv.Object == target.object &&
+						priv.Privilege == privilege && priv.Grantee == grantee
+				})
+
+				// REVOKE GRANT OPTION FOR keeps the privilege itself
+				if grantOptionOnly {
+					if i != -1 {
+						schema.Privileges[i].Grantable = false
+					}
+					continue
+				}
+
+				if i != -1 {
+					schema.Privileges = slices.Delete(schema.Privileges, i, i+1)
+				}
+				if defaultPrivilege(schema, target, grantee, privilege) {
+					schema.Privileges = append(schema.Privileges, &models.Privilege{
+						ObjectType: target.objectType,
+						Object:     target.object,
+						Grantee:    grantee,
+						Privilege:  privilege,
+						Revoked:    true,
+					})
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseOwnerTo parses ALTER TABLE, SEQUENCE, FUNCTION, SCHEMA ... OWNER TO
+func (p *SQLParser) parseOwnerTo(schema *models.Schema, stmt string) error {
+	matches := p.ownerToRegex.FindStringSubmatch(stmt)
+	if len(matches) < 4 {
+		return fmt.Errorf("invalid OWNER TO statement")
+	}
+
+	objectType := strings.Join(strings.Fields(strings.ToUpper(matches[1])), " ")
+	switch objectType {
+	case "VIEW", "MATERIALIZED VIEW", "FOREIGN TABLE":
+		objectType = "TABLE"
+	}
+
+	target := p.grantObject(schema, objectType, matches[2])
+	owner := p.cleanIdentifier(matches[3])
+
+	for _, o := range schema.Owners {
+		if o.ObjectType == target.objectType && o.Object == target.object {
+			o.Owner = owner
+			return nil
+		}
+	}
+
+	schema.Owners = append(schema.Owners, &models.Owner{
+		ObjectType: target.objectType,
+		Object:     target.object,
+		Owner:      owner,
+	})
+	return nil
+}
+
+// parseCreatePolicy parses a CREATE POLICY statement, adding the policy to its table
+func (p *SQLParser) parseCreatePolicy(schema *models.Schema, stmt string) error {
+	matches := p.policyRegex.FindStringSubmatch(stmt)
+	if len(matches) < 4 {
+		return fmt.Errorf("invalid CREATE POLICY statement")
+	}
+
+	policy := &models.Policy{
+		Name:    p.cleanIdentifier(matches[1]),
+		Table:   p.cleanIdentifier(matches[2]),
+		Command: "ALL",
+	}
+
+	// the expressions come last, and everything before them is options
+	options := matches[3]
+	for _, clause := range []struct {
+		regex *regexp.Regexp
+		expr  *string
+	}{
+		{p.policyWithCheckRegex, &policy.WithCheck},
+		{p.policyUsingRegex, &policy.Using},
+	} {
+		loc := clause.regex.FindStringIndex(options)
+		if loc == nil {
+			continue
+		}
+
+		end := p.closingParen(options, loc[1]-1)
+		if end == -1 {
+			return fmt.Errorf("unterminated expression in policy %s", policy.Name)
+		}
+		*clause.expr = strings.TrimSpace(options[loc[1]:end])
+		options = options[:loc[0]]
+	}
+
+	optionMatches := p.policyOptionsRegex.FindStringSubmatch(options)
+	if optionMatches == nil {
+		return fmt.Errorf("invalid options for policy %s: %s", policy.Name, strings.TrimSpace(options))
+	}
+
+	policy.Restrictive = strings.EqualFold(optionMatches[1], "RESTRICTIVE")
+	if optionMatches[2] != "" {
+		policy.Command = strings.ToUpper(optionMatches[2])
+	}
+
+	// a policy for PUBLIC applies to every role, the same as naming none
+	if roles := p.parseColumnList(optionMatches[3]); optionMatches[3] != "" && !(len(roles) == 1 && strings.EqualFold(roles[0], "PUBLIC")) {
+		policy.Roles = roles
+	}
+
+	for _, table := range schema.Tables {
+		if table.Name == policy.Table {
+			table.Policies = append(table.Policies, policy)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("table %s not found for CREATE POLICY", policy.Table)
+}
+
+// parseCreateExtension parses a CREATE EXTENSION statement. The schema and version are
+// left empty when the statement doesn't pin them
+func (p *SQLParser) parseCreateExtension(schema *models.Schema, stmt string) error {
+	matches := p.extensionRegex.FindStringSubmatch(stmt)
+	if len(matches) < 3 {
+		return fmt.Errorf("invalid CREATE EXTENSION statement")
+	}
+
+	ext := &models.Extension{
+		Name: p.cleanIdentifier(matches[1]),
+	}
+
+	if schemaMatches := p.extensionSchemaRegex.FindStringSubmatch(matches[2]); len(schemaMatches) > 1 {
+		ext.Schema = p.cleanIdentifier(schemaMatches[1])
+	}
+
+	if versionMatches := p.extensionVersionRegex.FindStringSubmatch(matches[2]); len(versionMatches) > 2 {
+		ext.Version = strings.ReplaceAll(versionMatches[1], "''", "'") + p.cleanIdentifier(versionMatches[2])
+	}
+
+	schema.Extensions = append(schema.Extensions, ext)
+	return nil
+}
+
+// parseCreateType parses the enum and composite forms of CREATE TYPE
+func (p *SQLParser) parseCreateType(schema *models.Schema, stmt string) error {
+	loc := p.createTypeRegex.FindStringSubmatchIndex(stmt)
+	if loc == nil {
+		// range, base and shell types aren't tracked
+		return nil
+	}
+
+	typeName := p.cleanIdentifier(stmt[loc[2]:loc[3]])
+
+	end := p.closingParen(stmt, loc[1]-1)
+	if end == -1 {
+		return fmt.Errorf("unterminated definition for type %s", typeName)
+	}
+	body := stmt[loc[1]:end]
+
+	if loc[4] != -1 {
+		enum := &models.Enum{
+			Name:   typeName,
+			Schema: schema.Name,
+			Labels: p.parseLiterals(body),
+		}
+		schema.Enums = append(schema.Enums, enum)
+		return nil
+	}
+
+	composite := &models.CompositeType{
+		Name:       typeName,
+		Schema:     schema.Name,
+		Attributes: make([]*models.Column, 0),
+	}
+
+	for _, part := range p.splitTableParts(body) {
+		fields := strings.Fields(part)
+		if len(fields) < 2 {
+			continue
+		}
+
+		composite.Attributes = append(composite.Attributes, &models.Column{
+			Name:     p.cleanIdentifier(fields[0]),
+			DataType: strings.Join(fields[1:], " "),
+		})
+	}
+
+	schema.CompositeTypes = append(schema.CompositeTypes, composite)
+	return nil
+}
+
+// parseLiterals returns the contents of the single-quoted literals in s
+func (p *SQLParser) parseLiterals(s string) []string {
+	literals := make([]string, 0)
+	for _, match := range p.quotedLiteralRegex.FindAllStringSubmatch(s, -1) {
+		literals = append(literals, strings.ReplaceAll(match[1], "''", "'"))
+	}
+	return literals
+}
+
+// parseCreateDomain parses a CREATE DOMAIN statement with its default, NOT NULL and CHECK constraints
+func (p *SQLParser) parseCreateDomain(schema *models.Schema, stmt string) error {
+	matches := p.domainRegex.FindStringSubmatch(stmt)
+	if len(matches) < 3 {
+		return fmt.Errorf("invalid CREATE DOMAIN statement")
+	}
+
+	domain := &models.Domain{
+		Name:        p.cleanIdentifier(matches[1]),
+		Schema:      schema.Name,
+		BaseType:    strings.TrimSpace(matches[2]),
+		Constraints: make([]*models.Constraint, 0),
+	}
+
+	// checks are cut out first, so that their expressions can't be mistaken for other options
+	options := matches[3]
+	var rest strings.Builder
+	for {
+		loc := p.domainCheckRegex.FindStringSubmatchIndex(options)
+		if loc == nil {
+			break
+		}
+
+		end := p.closingParen(options, loc[1]-1)
+		if end == -1 {
+			return fmt.Errorf("unterminated CHECK in domain %s", domain.Name)
+		}
+
+		// PostgreSQL names unnamed domain checks <domain>_check, numbering any repeats
+		name := domain.Name + "_check"
+		for n := 1; slices.ContainsFunc(domain.Constraints, func(c *models.Constraint) bool { return c.Name == name }); n++ {
+			name = fmt.Sprintf("%s_check%d", domain.Name, n)
+		}
+		if loc[2] != -1 {
+			name = p.cleanIdentifier(options[loc[2]:loc[3]])
+		}
+
+		domain.Constraints = append(domain.Constraints, &models.Constraint{
+			Name:      name,
+			Type:      "CHECK",
+			CheckExpr: strings.TrimSpace(options[loc[1]:end]),
+		})
+
+		rest.WriteString(options[:loc[0]] + " ")
+		options = options[end+1:]
+	}
+	rest.WriteString(options)
+	options = rest.String()
+
+	if defaultMatches := p.domainDefaultRegex.FindStringSubmatch(options); len(defaultMatches) > 1 {
+		domain.DefaultValue = strings.TrimSpace(defaultMatches[1])
+	}
+	domain.NotNull = p.domainNotNullRegex.MatchString(options)
+
+	schema.Domains = append(schema.Domains, domain)
+	return nil
+}
+
+// parseAlterType parses ALTER TYPE ... ADD VALUE, which is how enums gain labels
+func (p *SQLParser) parseAlterType(schema *models.Schema, stmt string) error {
+	matches := p.alterTypeRegex.FindStringSubmatch(stmt)
+	if len(matches) < 5 {
+		// other type changes aren't tracked
+		return nil
+	}
+
+	typeName := p.cleanIdentifier(matches[1])
+	label := strings.ReplaceAll(matches[2], "''", "'")
+	neighbour := strings.ReplaceAll(matches[4], "''", "'")
+
+	for _, enum := range schema.Enums {
+		if enum.Name != typeName {
+			continue
+		}
+
+		if slices.Contains(enum.Labels, label) {
+			return nil
+		}
+
+		// without BEFORE or AFTER the label goes last
+		pos := len(enum.Labels)
+		if i := slices.Index(enum.Labels, neighbour); matches[3] != "" && i != -1 {
+			pos = i
+			if strings.EqualFold(matches[3], "AFTER") {
+				pos++
+			}
+		}
+		enum.Labels = slices.Insert(enum.Labels, pos, label)
+		return nil
+	}
+
+	return fmt.Errorf("enum %s not found for ALTER TYPE", typeName)
+}
+
 // parseAlterTable parses an ALTER TABLE statement
 func (p *SQLParser) parseAlterTable(schema *models.Schema, stmt string) error {
 	matches := p.alterTableRegex.FindStringSubmatch(stmt)
@@ -545,6 +1778,11 @@ func (p *SQLParser) parseAlterTable(schema *models.Schema, stmt string) error {
 	alterDef := strings.TrimSpace(matches[2])
 	alterDefUpper := strings.ToUpper(alterDef)
 
+	// trigger state changes apply to triggers, not the table definition
+	if triggerMatches := p.triggerStateRegex.FindStringSubmatch(alterDef); len(triggerMatches) > 3 {
+		return p.parseAlterTriggerState(schema, tableName, triggerMatches)
+	}
+
 	var exists bool = false
 	var table *models.Table
 	for i, t:= range schema.Tables {
@@ -575,6 +1813,10 @@ func (p *SQLParser) parseAlterTable(schema *models.Schema, stmt string) error {
 		return p.parseAlterAddConstraint(table, alterDef)
 	case strings.HasPrefix(alterDefUpper, "DROP CONSTRAINT"):
 		return p.parseAlterDropConstraint(table, alterDef)
+	case p.rowSecurityRegex.MatchString(alterDef):
+		return p.parseAlterRowSecurity(table, alterDef)
+	case p.attachPartitionRegex.MatchString(alterDef) || p.detachPartitionRegex.MatchString(alterDef):
+		return p.parseAlterPartition(schema, table, alterDef)
 	default:
 		// Log unsupported ALTER TABLE operation
 		fmt.Printf("Warning: unsupported ALTER TABLE operation: %s\n", alterDef)
@@ -583,6 +1825,76 @@ func (p *SQLParser) parseAlterTable(schema *models.Schema, stmt string) error {
 	return nil
 }
 
+// parseAlterPartition parses ATTACH PARTITION and DETACH PARTITION in ALTER TABLE, turning an
+// existing table into a partition of table or back into a standalone table
+func (p *SQLParser) parseAlterPartition(schema *models.Schema, table *models.Table, alterDef string) error {
+	attach := p.attachPartitionRegex.FindStringSubmatch(alterDef)
+
+	var partitionName string
+	if attach != nil {
+		partitionName = p.cleanIdentifier(attach[1])
+	} else {
+		partitionName = p.cleanIdentifier(p.detachPartitionRegex.FindStringSubmatch(alterDef)[1])
+	}
+
+	for _, partition := range schema.Tables {
+		if partition.Name != partitionName {
+			continue
+		}
+
+		if attach != nil {
+			partition.PartitionOf = table.Name
+			partition.PartitionBound = strings.Join(strings.Fields(attach[2]), " ")
+		} else {
+			partition.PartitionOf = ""
+			partition.PartitionBound = ""
+		}
+		return nil
+	}
+
+	return fmt.Errorf("table %s not found for partition of %s", partitionName, table.Name)
+}
+
+// parseAlterRowSecurity parses [NO] FORCE and ENABLE/DISABLE ROW LEVEL SECURITY in ALTER TABLE
+func (p *SQLParser) parseAlterRowSecurity(table *models.Table, alterDef string) error {
+	action := strings.Join(strings.Fields(strings.ToUpper(p.rowSecurityRegex.FindStringSubmatch(alterDef)[1])), " ")
+
+	switch action {
+	case "ENABLE":
+		table.RowSecurity = true
+	case "DISABLE":
+		table.RowSecurity = false
+	case "FORCE":
+		table.ForceRowSecurity = true
+	case "NO FORCE":
+		table.ForceRowSecurity = false
+	}
+
+	return nil
+}
+
+// parseAlterTriggerState parses ENABLE/DISABLE TRIGGER in ALTER TABLE
+func (p *SQLParser) parseAlterTriggerState(schema *models.Schema, tableName string, matches []string) error {
+	state := "ENABLED"
+	if strings.EqualFold(matches[1], "DISABLE") {
+		state = "DISABLED"
+	} else if matches[2] != "" {
+		state = strings.ToUpper(matches[2])
+	}
+
+	// ALL and USER address every trigger on the table
+	triggerName := p.cleanIdentifier(matches[3])
+	allTriggers := strings.EqualFold(triggerName, "ALL") || strings.EqualFold(triggerName, "USER")
+
+	for _, trigger := range schema.Triggers {
+		if trigger.Table == tableName && (allTriggers || trigger.Name == triggerName) {
+			trigger.State = state
+		}
+	}
+
+	return nil
+}
+
 // parseAlterAddColumn parses ADD COLUMN in ALTER TABLE
 func (p *SQLParser) parseAlterAddColumn(table *models.Table, alterDef string) error {
 	
@@ -608,8 +1920,46 @@ func (p *SQLParser) parseAlterDropColumn(table *models.Table, alterDef string) e
 
 // parseAlterColumn parses ALTER COLUMN in ALTER TABLE
 func (p *SQLParser) parseAlterColumn(table *models.Table, alterDef string) error {
-	//TODO: This would handle operations like ALTER COLUMN SET NOT NULL, SET DEFAULT, etc.
-	fmt.Printf("Warning: ALTER COLUMN not fully implemented: %s\n", alterDef)
+	matches := p.alterColumnRegex.FindStringSubmatch(alterDef)
+	if len(matches) < 3 {
+		return fmt.Errorf("invalid ALTER COLUMN definition")
+	}
+
+	columnName := p.cleanIdentifier(matches[1])
+	var column *models.Column
+	for _, c := range table.Columns {
+		if c.Name == columnName {
+			column = c
+			break
+		}
+	}
+	if column == nil {
+		return fmt.Errorf("column %s not found in table %s", columnName, table.Name)
+	}
+
+	action := strings.TrimSpace(matches[2])
+	actionUpper := strings.ToUpper(action)
+
+	// pg_dump adds identity columns this way rather than in CREATE TABLE
+	switch {
+	case strings.HasPrefix(actionUpper, "ADD GENERATED"):
+		if identityMatches := p.identityRegex.FindStringSubmatch(action); len(identityMatches) > 1 {
+			column.Identity = strings.ToUpper(strings.Join(strings.Fields(identityMatches[1]), " "))
+			column.IsNullable = false
+		}
+	case p.setGeneratedRegex.MatchString(action):
+		identity := p.setGeneratedRegex.FindStringSubmatch(action)[1]
+		column.Identity = strings.ToUpper(strings.Join(strings.Fields(identity), " "))
+	case strings.HasPrefix(actionUpper, "DROP IDENTITY"):
+		column.Identity = ""
+	case strings.HasPrefix(actionUpper, "DROP EXPRESSION"):
+		column.Generated = ""
+		column.Virtual = false
+	default:
+		//TODO: This would handle operations like ALTER COLUMN SET NOT NULL, SET DEFAULT, etc.
+		fmt.Printf("Warning: ALTER COLUMN not fully implemented: %s\n", alterDef)
+	}
+
 	return nil
 }
 
@@ -655,6 +2005,10 @@ func (p *SQLParser) cleanIdentifier(identifier string) string {
 	if strings.HasPrefix(identifier, "`") && strings.HasSuffix(identifier, "`") {
 		identifier = identifier[1 : len(identifier)-1]
 	}
+	// Remove square brackets, which SQLite accepts for compatibility with SQL Server
+	if strings.HasPrefix(identifier, "[") && strings.HasSuffix(identifier, "]") {
+		identifier = identifier[1 : len(identifier)-1]
+	}
 	return identifier
 }
 
@@ -667,6 +2021,13 @@ func (p *SQLParser) Parse(content string) (*models.Schema, error) {
 		Indexes:   make([]*models.Index, 0),
 		Functions: make([]*models.Function, 0),
 		Sequences: make([]*models.Sequence, 0),
+
+		Enums:          make([]*models.Enum, 0),
+		Domains:        make([]*models.Domain, 0),
+		CompositeTypes: make([]*models.CompositeType, 0),
+		Extensions:     make([]*models.Extension, 0),
+		Privileges:     make([]*models.Privilege, 0),
+		Owners:         make([]*models.Owner, 0),
 	}
 
 	//TODO: complete
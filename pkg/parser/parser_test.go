@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Richd0tcom/schedrift/internal/models"
+)
+
+func parse(t *testing.T, sql string) *models.Schema {
+	t.Helper()
+	schema, err := NewSQLParser().Parse(sql)
+	if err != nil {
+		t.Fatalf("error parsing: %v", err)
+	}
+	return schema
+}
+
+func TestRevokeDefaultPrivilege(t *testing.T) {
+	schema := parse(t, `
+CREATE FUNCTION add(a integer, b integer) RETURNS integer LANGUAGE sql AS $$ SELECT a + b $$;
+CREATE TABLE posts (id integer);
+ALTER TABLE posts OWNER TO app_owner;
+
+REVOKE EXECUTE ON FUNCTION add(integer, integer) FROM PUBLIC;
+REVOKE TRUNCATE ON posts FROM app_owner;
+GRANT SELECT ON posts TO reporting;
+REVOKE SELECT ON posts FROM reporting;
+REVOKE INSERT ON posts FROM reporting;
+`)
+
+	// revoking a grant that isn't a default, or was never made, leaves nothing behind
+	want := []*models.Privilege{
+		{ObjectType: "FUNCTION", Object: "add(integer, integer)", Grantee: "public", Privilege: "EXECUTE", Revoked: true},
+		{ObjectType: "TABLE", Object: "posts", Grantee: "app_owner", Privilege: "TRUNCATE", Revoked: true},
+	}
+	if !reflect.DeepEqual(schema.Privileges, want) {
+		for _, priv := range schema.Privileges {
+			t.Errorf("got %+v", *priv)
+		}
+	}
+}
+
+func TestGrantRestoresRevokedDefault(t *testing.T) {
+	schema := parse(t, `
+CREATE FUNCTION add(a integer, b integer) RETURNS integer LANGUAGE sql AS $$ SELECT a + b $$;
+CREATE FUNCTION sub(a integer, b integer) RETURNS integer LANGUAGE sql AS $$ SELECT a - b $$;
+
+REVOKE ALL ON FUNCTION add(integer, integer), sub(integer, integer) FROM PUBLIC;
+GRANT EXECUTE ON FUNCTION add(integer, integer) TO PUBLIC;
+GRANT EXECUTE ON FUNCTION sub(integer, integer) TO PUBLIC WITH GRANT OPTION;
+`)
+
+	// the restored default is only listed when it gained the grant option
+	want := []*models.Privilege{
+		{ObjectType: "FUNCTION", Object: "sub(integer, integer)", Grantee: "public", Privilege: "EXECUTE", Grantable: true},
+	}
+	if !reflect.DeepEqual(schema.Privileges, want) {
+		for _, priv := range schema.Privileges {
+			t.Errorf("got %+v", *priv)
+		}
+	}
+}
+
+func TestGeneratedColumnIgnoresQuotedAs(t *testing.T) {
+	schema := parse(t, `
+CREATE TABLE notes (
+	id integer,
+	note text DEFAULT 'as (x)' NOT NULL,
+	label text DEFAULT 'GENERATED ALWAYS AS (y)',
+	total integer AS (id * 2),
+	doubled integer GENERATED ALWAYS AS (id * 2) STORED
+);
+`)
+
+	tests := []struct {
+		column    string
+		def       string
+		generated string
+		nullable  bool
+	}{
+		{"note", "'as (x)'", "", false},
+		{"label", "'GENERATED ALWAYS AS (y)'", "", true},
+		{"total", "", "id * 2", true},
+		{"doubled", "", "id * 2", true},
+	}
+
+	columns := schema.Tables[0].Columns
+	for _, tt := range tests {
+		var column *models.Column
+		for _, c := range columns {
+			if c.Name == tt.column {
+				column = c
+			}
+		}
+		if column == nil {
+			t.Errorf("column %s was not parsed", tt.column)
+			continue
+		}
+		if column.DefaultValue != tt.def || column.Generated != tt.generated || column.IsNullable != tt.nullable {
+			t.Errorf("%s: got default %q, generated %q, nullable %v; want %q, %q, %v",
+				tt.column, column.DefaultValue, column.Generated, column.IsNullable, tt.def, tt.generated, tt.nullable)
+		}
+	}
+}